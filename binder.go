@@ -1,44 +1,774 @@
 package emir
 
 import (
+	"bytes"
+	"encoding"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pasztorpisti/qs"
+	"github.com/vmihailenco/msgpack/v4"
+	"google.golang.org/protobuf/proto"
 )
 
 // Bind implements the Binder#Bind function. Binding is done in following order:
-// Binder will bind the body first then binds the query params
+// Binder will bind the body first then binds the query params, headers, cookies and path params
+// Values bound later overwrite the earlier ones if a field has multiple tags.
 // If the request method is not POST, PUT or PATCH then the binder will skip the body
-// Struct tag for query params will be "qs".
+// Body formats are JSON, XML, MessagePack, Protocol Buffers, form and multipart form.
+// Protocol Buffers bodies can be bound only into proto.Message values.
+// Struct tag for query params, form and multipart form values will be "qs".
+// Multipart files are bound into *multipart.FileHeader and []*multipart.FileHeader fields by their "qs" names.
+// Struct tags for path params, headers and cookies will be "path", "header" and "cookie".
+// Fields that have these tags are bound only from their sources, their values in the body and the query are skipped
+// unless the fields also have the tag of the format like "json" or "qs".
 func (*DefaultBinder) Bind(c *Context, v interface{}) error {
 	contentType := B2S(c.ReqHeader().ContentType())
 
 	if c.IsPost() || c.IsPut() || c.IsPatch() {
 		switch {
 		case strings.HasPrefix(contentType, ContentTypeApplicationJSON):
-			if marshaler, ok := v.(json.Unmarshaler); ok {
-				if err := marshaler.UnmarshalJSON(c.PostBody()); err != nil {
-					return err
-				}
-			}
-
-			if err := json.Unmarshal(c.PostBody(), v); err != nil {
-				return err
+			body := skipJSONMembers(c.jsonCodec(), c.PostBody(), protectedNames(v, "json"))
+			if err := c.jsonCodec().Unmarshal(body, v); err != nil {
+				return decodeError(err, v)
 			}
 		case strings.HasPrefix(contentType, ContentTypeApplicationXML) ||
 			strings.HasPrefix(contentType, ContentTypeTextXML):
 
-			if err := xml.Unmarshal(c.PostBody(), v); err != nil {
-				return err
+			body := skipXMLElements(c.PostBody(), protectedNames(v, "xml"))
+			if err := xml.Unmarshal(body, v); err != nil {
+				return xmlDecodeError(err, body, v)
+			}
+		case strings.HasPrefix(contentType, ContentTypeApplicationMsgPack):
+			if err := msgpack.Unmarshal(skipMsgpackKeys(c.PostBody(), protectedNames(v, "msgpack")), v); err != nil {
+				return NewBasicError(StatusBadRequest, "malformed MessagePack body")
+			}
+		case strings.HasPrefix(contentType, ContentTypeApplicationProtobuf):
+			message, ok := v.(proto.Message)
+			if !ok {
+				return NewBasicError(StatusUnsupportedMediaType, "Protocol Buffers body can't be bound")
+			}
+
+			if err := proto.Unmarshal(c.PostBody(), message); err != nil {
+				return NewBasicError(StatusBadRequest, "malformed Protocol Buffers body")
 			}
 		case strings.HasPrefix(contentType, ContentTypeApplicationForm):
-			if err := qs.UnmarshalValues(v, ConvertArgsToValues(c.PostArgs())); err != nil {
+			values := skipValues(ConvertArgsToValues(c.PostArgs()), protectedNames(v, "qs"))
+			if err := qsUnmarshaler.UnmarshalValues(v, values); err != nil {
+				return decodeError(err, v)
+			}
+		case strings.HasPrefix(contentType, ContentTypeMultipartForm):
+			if err := bindMultipart(c, v); err != nil {
 				return err
 			}
 		}
 	}
 
-	return qs.UnmarshalValues(v, ConvertArgsToValues(c.QueryArgs()))
+	if err := qsUnmarshaler.UnmarshalValues(v, skipValues(ConvertArgsToValues(c.QueryArgs()), protectedNames(v, "qs"))); err != nil {
+		return decodeError(err, v)
+	}
+
+	if err := bindTag(v, "header", func(name string) []string {
+		if value := c.ReqHeader().Peek(name); len(value) != 0 {
+			return []string{string(value)}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := bindTag(v, "cookie", func(name string) []string {
+		if value := c.ReqHeader().Cookie(name); len(value) != 0 {
+			return []string{string(value)}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return bindTag(v, "path", func(name string) []string {
+		if value := c.Param(name); value != "" {
+			return []string{value}
+		}
+
+		return nil
+	})
+}
+
+var qsEntryRegexp = regexp.MustCompile(`url\.Values entry "([^"]*)"`)
+
+// decodeError converts given decoding error to a BasicError with status 400.
+// Details of the error carry the offending field and its expected type when they are known.
+func decodeError(err error, v interface{}) error {
+	switch e := err.(type) {
+	case *BasicError:
+		return e
+	case *json.UnmarshalTypeError:
+		return newBindError("invalid value for field \""+e.Field+"\"", e.Field, e.Type.String())
+	case *json.SyntaxError:
+		return NewBasicError(StatusBadRequest, "malformed JSON body")
+	case *xml.SyntaxError:
+		return NewBasicError(StatusBadRequest, "malformed XML body")
+	case *strconv.NumError:
+		return NewBasicError(StatusBadRequest, "invalid value \""+e.Num+"\"")
+	}
+
+	if field, ok := qs.IsRequiredFieldError(err); ok {
+		return newBindError("missing field \""+field+"\"", field, qsFieldType(v, field))
+	}
+
+	if match := qsEntryRegexp.FindStringSubmatch(err.Error()); match != nil {
+		return newBindError("invalid value for field \""+match[1]+"\"", match[1], qsFieldType(v, match[1]))
+	}
+
+	return NewBasicError(StatusBadRequest, "malformed request")
+}
+
+// xmlDecodeError converts given XML decoding error to a BasicError with status 400.
+// encoding/xml doesn't report the field of the invalid values, so the field is looked up by the invalid value.
+func xmlDecodeError(err error, body []byte, v interface{}) error {
+	var value string
+	switch e := err.(type) {
+	case *strconv.NumError:
+		value = e.Num
+	case *time.ParseError:
+		value = e.Value
+	default:
+		return decodeError(err, v)
+	}
+
+	if field, expected := xmlValueField(body, v, value); field != "" {
+		return newBindError("invalid value for field \""+field+"\"", field, expected)
+	}
+
+	return decodeError(err, v)
+}
+
+// xmlValueField returns the dot separated element path and the type of the first field of given struct pointer
+// whose element or attribute has given value. The root element isn't included in the path.
+func xmlValueField(body []byte, v interface{}, value string) (string, string) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	var (
+		path  []string
+		types []reflect.Type
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", ""
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			elementType := t
+			if len(types) != 0 {
+				elementType = xmlFieldType(types[len(types)-1], token.Name.Local)
+			}
+
+			path = append(path, token.Name.Local)
+			types = append(types, elementType)
+
+			for _, attr := range token.Attr {
+				if strings.TrimSpace(attr.Value) != value {
+					continue
+				}
+
+				if attrType := xmlFieldType(elementType, attr.Name.Local); attrType != nil {
+					return strings.Join(append(path[1:], attr.Name.Local), "."), attrType.String()
+				}
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+			types = types[:len(types)-1]
+		case xml.CharData:
+			if len(types) == 0 || strings.TrimSpace(string(token)) != value {
+				continue
+			}
+
+			elementType := types[len(types)-1]
+			if elementType == nil || len(path) < 2 {
+				continue
+			}
+
+			if elementType.Kind() != reflect.Struct || reflect.PtrTo(elementType).Implements(textUnmarshalerType) {
+				return strings.Join(path[1:], "."), elementType.String()
+			}
+		}
+	}
+}
+
+// xmlFieldType returns the type of the field of given struct type that is named by given element or attribute name.
+// Pointers are dereferenced and the element type is returned for the slices, since every element is a value.
+func xmlFieldType(t reflect.Type, name string) reflect.Type {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fieldType reflect.Type
+	visitFields(t, func(field reflect.StructField) {
+		fieldName, _ := splitTag(field.Tag.Get("xml"))
+		switch fieldName {
+		case "-":
+			return
+		case "":
+			fieldName = field.Name
+		}
+
+		if fieldType == nil && fieldName == name {
+			fieldType = field.Type
+		}
+	})
+
+	for fieldType != nil && (fieldType.Kind() == reflect.Ptr || (fieldType.Kind() == reflect.Slice && fieldType != bytesType)) {
+		fieldType = fieldType.Elem()
+	}
+
+	return fieldType
+}
+
+// newBindError returns a BasicError with status 400 which carries the offending field and its expected type
+func newBindError(message, field, expected string) error {
+	return &BasicError{
+		StatusCode:   StatusBadRequest,
+		ErrorMessage: message,
+		Details:      &BindErrorDetails{Field: field, Expected: expected},
+	}
+}
+
+// qsFieldType returns the type of the field of given struct pointer by its "qs" name
+func qsFieldType(v interface{}, name string) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return ""
+	}
+
+	expected := ""
+	visitFields(t, func(field reflect.StructField) {
+		fieldName, _ := splitTag(field.Tag.Get("qs"))
+		if fieldName == "" {
+			fieldName = snakeCase(field.Name)
+		}
+
+		if expected == "" && fieldName == name {
+			expected = field.Type.String()
+		}
+	})
+
+	return expected
+}
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType     = reflect.TypeOf([]*multipart.FileHeader(nil))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// qsUnmarshaler is the query string unmarshaler used by DefaultBinder.
+// It skips the fields of the types that qs cannot unmarshal like multipart files and nested structs
+// since these fields are bound from the other sources.
+var qsUnmarshaler = qs.NewUnmarshaler(&qs.UnmarshalOptions{
+	UnmarshalerFactory: &skippingUnmarshalerFactory{factory: qs.NewDefaultUnmarshalOptions().UnmarshalerFactory},
+})
+
+type skippingUnmarshalerFactory struct {
+	factory qs.UnmarshalerFactory
+}
+
+func (f *skippingUnmarshalerFactory) Unmarshaler(t reflect.Type, opts *qs.UnmarshalOptions) (qs.Unmarshaler, error) {
+	if t == fileHeaderType || t == fileHeadersType {
+		return skipUnmarshaler{}, nil
+	}
+
+	unmarshaler, err := f.factory.Unmarshaler(t, opts)
+	if err != nil {
+		return skipUnmarshaler{}, nil
+	}
+
+	return unmarshaler, nil
+}
+
+type skipUnmarshaler struct{}
+
+func (skipUnmarshaler) Unmarshal(reflect.Value, []string, *qs.UnmarshalOptions) error {
+	return nil
+}
+
+// bindMultipart binds the multipart form values and files into given value
+// after checking the upload limits of the route.
+func bindMultipart(c *Context, v interface{}) error {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return NewBasicError(StatusBadRequest, "malformed multipart form")
+	}
+
+	if err := checkUploadLimits(c.route, form); err != nil {
+		return err
+	}
+
+	if err := qsUnmarshaler.UnmarshalValues(v, skipValues(url.Values(form.Value), protectedNames(v, "qs"))); err != nil {
+		return decodeError(err, v)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	rv = rv.Elem()
+	for _, field := range fileFields(rv.Type()) {
+		files := form.File[field.name]
+		if len(files) == 0 {
+			continue
+		}
+
+		fv := rv.FieldByIndex(field.index)
+		if fv.Type() == fileHeaderType {
+			fv.Set(reflect.ValueOf(files[0]))
+			continue
+		}
+
+		fv.Set(reflect.ValueOf(files))
+	}
+
+	return nil
+}
+
+func checkUploadLimits(route *Route, form *multipart.Form) error {
+	if route == nil {
+		return nil
+	}
+
+	count := 0
+	for _, files := range form.File {
+		count += len(files)
+
+		if route.MaxUploadFileSize <= 0 {
+			continue
+		}
+
+		for _, file := range files {
+			if file.Size > route.MaxUploadFileSize {
+				return NewBasicError(StatusRequestEntityTooLarge, "file \""+file.Filename+"\" is too large")
+			}
+		}
+	}
+
+	if route.MaxUploadFiles > 0 && count > route.MaxUploadFiles {
+		return NewBasicError(StatusRequestEntityTooLarge, "too many files")
+	}
+
+	return nil
+}
+
+var fileFieldsCache sync.Map
+
+// fileFields returns the multipart file fields of given struct type named by their "qs" names.
+func fileFields(t reflect.Type) []taggedField {
+	if fields, ok := fileFieldsCache.Load(t); ok {
+		return fields.([]taggedField)
+	}
+
+	var fields []taggedField
+	var visit func(t reflect.Type, index []int)
+	visit = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				visit(field.Type, fieldIndex)
+				continue
+			}
+
+			if field.PkgPath != "" || (field.Type != fileHeaderType && field.Type != fileHeadersType) {
+				continue
+			}
+
+			name, _ := splitTag(field.Tag.Get("qs"))
+			switch name {
+			case "-":
+				continue
+			case "":
+				name = snakeCase(field.Name)
+			}
+
+			fields = append(fields, taggedField{index: fieldIndex, name: name})
+		}
+	}
+	visit(t, nil)
+
+	fileFieldsCache.Store(t, fields)
+
+	return fields
+}
+
+type taggedField struct {
+	index []int
+	name  string
+}
+
+type taggedFieldsKey struct {
+	t   reflect.Type
+	tag string
+}
+
+var taggedFieldsCache sync.Map
+
+// taggedFields returns the fields of given struct type that have given tag.
+// Fields of the embedded structs are included.
+func taggedFields(t reflect.Type, tag string) []taggedField {
+	key := taggedFieldsKey{t: t, tag: tag}
+	if fields, ok := taggedFieldsCache.Load(key); ok {
+		return fields.([]taggedField)
+	}
+
+	var fields []taggedField
+	var visit func(t reflect.Type, index []int)
+	visit = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			if name := field.Tag.Get(tag); name != "" && name != "-" && field.PkgPath == "" {
+				fields = append(fields, taggedField{index: fieldIndex, name: name})
+				continue
+			}
+
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				visit(field.Type, fieldIndex)
+			}
+		}
+	}
+	visit(t, nil)
+
+	taggedFieldsCache.Store(key, fields)
+
+	return fields
+}
+
+// sourceTags are the tags of the fields that are bound from the path params, headers and cookies
+var sourceTags = []string{"path", "header", "cookie"}
+
+var protectedNamesCache sync.Map
+
+// protectedNames returns the names of the fields of given struct pointer that have one of the source tags
+// but don't have given format tag, as the format names them.
+// The decoders skip the values with these names, so the clients can neither set these fields
+// nor fail the binding with invalid values for them.
+func protectedNames(v interface{}, formatTag string) []string {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	t = t.Elem()
+	key := taggedFieldsKey{t: t, tag: formatTag}
+	if names, ok := protectedNamesCache.Load(key); ok {
+		return names.([]string)
+	}
+
+	var names []string
+	for _, tag := range sourceTags {
+		for _, field := range taggedFields(t, tag) {
+			structField := t.FieldByIndex(field.index)
+			if structField.Tag.Get(formatTag) != "" {
+				continue
+			}
+
+			name := structField.Name
+			if formatTag == "qs" {
+				name = snakeCase(name)
+			}
+
+			names = append(names, name)
+		}
+	}
+
+	protectedNamesCache.Store(key, names)
+
+	return names
+}
+
+// skipValues returns given values without the protected names.
+// Given values are copied if a value is skipped, so they can be shared with the request.
+func skipValues(values url.Values, names []string) url.Values {
+	var filtered url.Values
+	for _, name := range names {
+		if _, ok := values[name]; !ok {
+			continue
+		}
+
+		if filtered == nil {
+			filtered = make(url.Values, len(values))
+			for key, value := range values {
+				filtered[key] = value
+			}
+		}
+
+		delete(filtered, name)
+	}
+
+	if filtered == nil {
+		return values
+	}
+
+	return filtered
+}
+
+// skipJSONMembers removes the members of given JSON object whose names match the protected names.
+// Names are matched case-insensitively like encoding/json does.
+// Given body is returned if it isn't an object, so the decoder reports its errors.
+func skipJSONMembers(codec JSONCodec, body []byte, names []string) []byte {
+	if len(names) == 0 {
+		return body
+	}
+
+	var members map[string]json.RawMessage
+	if err := codec.Unmarshal(body, &members); err != nil {
+		return body
+	}
+
+	skipped := false
+	for key := range members {
+		for _, name := range names {
+			if strings.EqualFold(key, name) {
+				delete(members, key)
+				skipped = true
+
+				break
+			}
+		}
+	}
+
+	if !skipped {
+		return body
+	}
+
+	filtered, err := codec.Marshal(members)
+	if err != nil {
+		return body
+	}
+
+	return filtered
+}
+
+// skipXMLElements removes the child elements of the root element whose names are the protected names.
+// Given body is returned if it is malformed, so the decoder reports its errors.
+func skipXMLElements(body []byte, names []string) []byte {
+	if len(names) == 0 {
+		return body
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	var filtered []byte
+	last, depth := int64(0), 0
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return body
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if depth == 1 && containsString(names, token.Name.Local) {
+				if err := decoder.Skip(); err != nil {
+					return body
+				}
+
+				filtered = append(filtered, body[last:start]...)
+				last = decoder.InputOffset()
+
+				continue
+			}
+
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	if filtered == nil {
+		return body
+	}
+
+	return append(filtered, body[last:]...)
+}
+
+// skipMsgpackKeys removes the keys of given MessagePack map that are the protected names.
+// Given body is returned if it isn't a map, so the decoder reports its errors.
+func skipMsgpackKeys(body []byte, names []string) []byte {
+	if len(names) == 0 {
+		return body
+	}
+
+	var members map[string]interface{}
+	if err := msgpack.Unmarshal(body, &members); err != nil {
+		return body
+	}
+
+	skipped := false
+	for _, name := range names {
+		if _, ok := members[name]; ok {
+			delete(members, name)
+			skipped = true
+		}
+	}
+
+	if !skipped {
+		return body
+	}
+
+	filtered, err := msgpack.Marshal(members)
+	if err != nil {
+		return body
+	}
+
+	return filtered
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bindTag sets the fields of given struct pointer that have given tag.
+// Values of the fields are looked up by the tag value.
+func bindTag(v interface{}, tag string, lookup func(name string) []string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range taggedFields(rv.Type(), tag) {
+		values := lookup(field.name)
+		if len(values) == 0 {
+			continue
+		}
+
+		fv := rv.FieldByIndex(field.index)
+		if err := setField(fv, values); err != nil {
+			return newBindError("invalid "+tag+" parameter \""+field.name+"\"", field.name, fv.Type().String())
+		}
+	}
+
+	return nil
+}
+
+// setField sets given field by parsing given values.
+// Slice fields are set with all values, the others are set with the first value.
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+
+		return setField(field.Elem(), values)
+	}
+
+	if field.CanAddr() {
+		if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(values[0]))
+		}
+	}
+
+	if field.Kind() == reflect.Slice && field.Type() != bytesType {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setField(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+
+		return nil
+	}
+
+	return setValue(field, values[0])
+}
+
+// setValue sets given field by parsing given value
+func setValue(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Slice:
+		field.SetBytes([]byte(value))
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(v)
+	default:
+		return fmt.Errorf("emir: unsupported field type %s", field.Type())
+	}
+
+	return nil
 }
@@ -1,9 +1,19 @@
 package emir
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/valyala/fasthttp"
+	"github.com/vmihailenco/msgpack/v4"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func Test_JSONBind(t *testing.T) {
@@ -136,3 +146,407 @@ func Test_XMLBind(t *testing.T) {
 
 	e.Handler()(ctx)
 }
+
+func Test_MsgPackBind(t *testing.T) {
+	e := New(Config{})
+
+	e.POST("/", func(c *Context) error {
+		v := struct {
+			Test  string `msgpack:"test"`
+			Test1 string `qs:"Test1"`
+		}{}
+
+		if err := c.Bind(&v); err != nil {
+			t.Fatal(err)
+		}
+
+		if v.Test != "test" || v.Test1 != "test" {
+			t.Fatalf("unexpected value. expected: %s, got: %s and %s", "test", v.Test, v.Test1)
+		}
+
+		return nil
+	})
+
+	body, err := msgpack.Marshal(map[string]string{"test": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/?Test1=test")
+	ctx.Request.Header.SetContentType(ContentTypeApplicationMsgPack)
+	ctx.Request.SetBody(body)
+	ctx.Request.Header.SetMethod(MethodPost)
+
+	e.Handler()(ctx)
+}
+
+func Test_ProtobufBind(t *testing.T) {
+	e := New(Config{})
+
+	e.POST("/", func(c *Context) error {
+		v := &wrapperspb.StringValue{}
+		if err := c.Bind(v); err != nil {
+			return err
+		}
+
+		if v.Value != "test" {
+			t.Fatalf("unexpected value. expected: %s, got: %s", "test", v.Value)
+		}
+
+		return nil
+	})
+
+	e.POST("/struct", func(c *Context) error {
+		var v struct{}
+
+		return c.Bind(&v)
+	})
+
+	body, err := proto.Marshal(wrapperspb.String("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := e.Handler()
+
+	tests := []struct {
+		uri    string
+		body   []byte
+		status int
+	}{
+		{"/", body, StatusOK},
+		{"/", []byte{0xff}, StatusBadRequest},
+		{"/struct", body, StatusUnsupportedMediaType},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.SetRequestURI(test.uri)
+		ctx.Request.Header.SetContentType(ContentTypeApplicationProtobuf)
+		ctx.Request.SetBody(test.body)
+		ctx.Request.Header.SetMethod(MethodPost)
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s. expected: %d, got: %d", test.uri, test.status, code)
+		}
+	}
+}
+
+func Test_ParamHeaderCookieBind(t *testing.T) {
+	e := New(Config{})
+
+	e.NewGroup("/tenants").PUT("/{id}", func(c *Context) error {
+		v := struct {
+			ID      int64         `path:"id"`
+			Tenant  string        `header:"X-Tenant"`
+			Session string        `cookie:"session"`
+			Timeout time.Duration `header:"X-Timeout"`
+			Name    string        `json:"name"`
+		}{}
+
+		if err := c.Bind(&v); err != nil {
+			t.Fatal(err)
+		}
+
+		if v.ID != 42 || v.Tenant != "acme" || v.Session != "secret" || v.Timeout != time.Second || v.Name != "test" {
+			t.Fatalf("unexpected value: %+v", v)
+		}
+
+		return nil
+	})
+
+	e.GET("/{id}", func(c *Context) error {
+		v := struct {
+			ID int `path:"id"`
+		}{}
+
+		return c.Bind(&v)
+	})
+
+	handler := e.Handler()
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/tenants/42")
+	ctx.Request.Header.SetMethod(MethodPut)
+	ctx.Request.Header.SetContentType(ContentTypeApplicationJSON)
+	ctx.Request.Header.Set("X-Tenant", "acme")
+	ctx.Request.Header.Set("X-Timeout", "1s")
+	ctx.Request.Header.SetCookie("session", "secret")
+	ctx.Request.SetBodyString(`{"name": "test"}`)
+
+	handler(ctx)
+
+	ctx = new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/abc")
+	ctx.Request.Header.SetMethod(MethodGet)
+
+	handler(ctx)
+
+	if code := ctx.Response.StatusCode(); code != StatusBadRequest {
+		t.Errorf("unexpected status code. expected: %d, got: %d", StatusBadRequest, code)
+	}
+}
+
+func Test_SourceFieldsNotSpoofed(t *testing.T) {
+	type request struct {
+		ID      int    `path:"id"`
+		Tenant  string `header:"X-Tenant"`
+		Session string `cookie:"session"`
+		Name    string `json:"name" xml:"name" msgpack:"name"`
+		Both    string `header:"X-Both" qs:"both"`
+	}
+
+	var v request
+	e := New(Config{})
+	e.POST("/{id}", func(c *Context) error {
+		v = request{}
+		return c.Bind(&v)
+	})
+
+	handler := e.Handler()
+
+	msgpackBody, err := msgpack.Marshal(map[string]interface{}{"ID": "abc", "Tenant": "evil", "Session": "evil", "name": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid values of the source fields must not fail the binding either
+	tests := []struct {
+		contentType string
+		body        string
+	}{
+		{ContentTypeApplicationJSON, `{"Tenant": "evil", "session": "evil", "ID": "abc", "name": "test"}`},
+		{ContentTypeApplicationForm, "tenant=evil&session=evil&id=abc"},
+		{ContentTypeApplicationXML, "<request><ID>abc</ID><Tenant>evil</Tenant><Session><x>evil</x></Session><name>test</name></request>"},
+		{ContentTypeApplicationMsgPack, string(msgpackBody)},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.SetRequestURI("/42?tenant=evil&session=evil&id=abc&both=query")
+		ctx.Request.Header.SetMethod(MethodPost)
+		ctx.Request.Header.SetContentType(test.contentType)
+		ctx.Request.SetBodyString(test.body)
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != StatusOK {
+			t.Fatalf("unexpected status code for %s: %d, body: %s", test.contentType, code, ctx.Response.Body())
+		}
+
+		if v.ID != 42 || v.Tenant != "" || v.Session != "" || v.Both != "query" {
+			t.Errorf("source fields are set by the request for %s: %+v", test.contentType, v)
+		}
+
+		if test.contentType != ContentTypeApplicationForm && v.Name != "test" {
+			t.Errorf("body isn't bound for %s: %+v", test.contentType, v)
+		}
+	}
+}
+
+func Test_XMLBindErrors(t *testing.T) {
+	type address struct {
+		Zip int `xml:"zip,attr"`
+	}
+
+	e := New(Config{})
+	e.POST("/", func(c *Context) error {
+		v := struct {
+			N       int       `xml:"n"`
+			Enabled bool      `xml:"enabled"`
+			Tags    []uint    `xml:"tag"`
+			Address *address  `xml:"address"`
+			Since   time.Time `xml:"since"`
+		}{}
+
+		return c.Bind(&v)
+	})
+
+	handler := e.Handler()
+
+	tests := []struct {
+		body     string
+		status   int
+		field    string
+		expected string
+	}{
+		{"<a><n>1</n><enabled>true</enabled><tag>1</tag><tag>2</tag></a>", StatusOK, "", ""},
+		{"<a><n>abc</n></a>", StatusBadRequest, "n", "int"},
+		{"<a><n>1</n><enabled>yes</enabled></a>", StatusBadRequest, "enabled", "bool"},
+		{"<a><tag>1</tag><tag>-2</tag></a>", StatusBadRequest, "tag", "uint"},
+		{`<a><address zip="x1"></address></a>`, StatusBadRequest, "address.zip", "int"},
+		{"<a><since>yesterday</since></a>", StatusBadRequest, "since", "time.Time"},
+		{"<a><n>1</a>", StatusBadRequest, "", ""},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.SetRequestURI("/")
+		ctx.Request.Header.SetMethod(MethodPost)
+		ctx.Request.Header.SetContentType(ContentTypeApplicationXML)
+		ctx.Request.SetBodyString(test.body)
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s. expected: %d, got: %d", test.body, test.status, code)
+		}
+
+		if test.field == "" {
+			continue
+		}
+
+		body := struct {
+			Details BindErrorDetails `json:"details"`
+		}{}
+
+		if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+			t.Fatal(err)
+		}
+
+		if body.Details.Field != test.field || body.Details.Expected != test.expected {
+			t.Errorf("unexpected details for %s: %+v", test.body, body.Details)
+		}
+	}
+}
+
+func Test_MultipartBind(t *testing.T) {
+	e := New(Config{})
+	dir := t.TempDir()
+
+	e.POST("/upload", func(c *Context) error {
+		v := struct {
+			Title       string                  `qs:"title"`
+			Avatar      *multipart.FileHeader   `qs:"avatar"`
+			Attachments []*multipart.FileHeader `qs:"attachments"`
+		}{}
+
+		if err := c.Bind(&v); err != nil {
+			return err
+		}
+
+		if v.Title != "test" || v.Avatar == nil || len(v.Attachments) != 2 {
+			t.Fatalf("unexpected value: %+v", v)
+		}
+
+		return c.SaveFile(v.Avatar, filepath.Join(dir, v.Avatar.Filename))
+	}).UploadLimits(3, 16)
+
+	handler := e.Handler()
+
+	newCtx := func(files ...string) *fasthttp.RequestCtx {
+		body := new(bytes.Buffer)
+		w := multipart.NewWriter(body)
+		w.WriteField("title", "test")
+		for i, file := range files {
+			name := "attachments"
+			if i == 0 {
+				name = "avatar"
+			}
+
+			part, _ := w.CreateFormFile(name, name+strconv.Itoa(i)+".txt")
+			part.Write([]byte(file))
+		}
+		w.Close()
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.SetRequestURI("/upload")
+		ctx.Request.Header.SetMethod(MethodPost)
+		ctx.Request.Header.SetContentType(w.FormDataContentType())
+		ctx.Request.SetBody(body.Bytes())
+
+		return ctx
+	}
+
+	ctx := newCtx("avatar", "a", "b")
+	handler(ctx)
+
+	if code := ctx.Response.StatusCode(); code != StatusOK {
+		t.Fatalf("unexpected status code. expected: %d, got: %d", StatusOK, code)
+	}
+
+	if content, err := ioutil.ReadFile(filepath.Join(dir, "avatar0.txt")); err != nil || string(content) != "avatar" {
+		t.Errorf("unexpected saved file: %s, error: %v", content, err)
+	}
+
+	ctx = newCtx("avatar", "a", "b", "c")
+	handler(ctx)
+
+	if code := ctx.Response.StatusCode(); code != StatusRequestEntityTooLarge {
+		t.Errorf("unexpected status code. expected: %d, got: %d", StatusRequestEntityTooLarge, code)
+	}
+
+	ctx = newCtx("this file is too large", "a", "b")
+	handler(ctx)
+
+	if code := ctx.Response.StatusCode(); code != StatusRequestEntityTooLarge {
+		t.Errorf("unexpected status code. expected: %d, got: %d", StatusRequestEntityTooLarge, code)
+	}
+}
+
+func Test_BindAndValidate(t *testing.T) {
+	type address struct {
+		City string `json:"city" validate:"required"`
+	}
+
+	e := New(Config{})
+	e.POST("/users", func(c *Context) error {
+		v := struct {
+			Name    string  `json:"name" validate:"required"`
+			Age     int     `json:"age"`
+			Limit   int     `qs:"limit"`
+			Address address `json:"address"`
+		}{}
+
+		return c.BindAndValidate(&v)
+	})
+
+	handler := e.Handler()
+
+	tests := []struct {
+		uri      string
+		body     string
+		status   int
+		field    string
+		expected string
+	}{
+		{"/users", `{"name": "emir", "address": {"city": "Istanbul"}}`, StatusOK, "", ""},
+		{"/users", `{"name": "emir", "age": "ten"}`, StatusBadRequest, "age", "int"},
+		{"/users", `{"name": `, StatusBadRequest, "", ""},
+		{"/users?limit=ten", `{"name": "emir"}`, StatusBadRequest, "limit", "int"},
+		{"/users", `{"address": {}}`, StatusUnprocessableEntity, "", ""},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.SetRequestURI(test.uri)
+		ctx.Request.Header.SetMethod(MethodPost)
+		ctx.Request.Header.SetContentType(ContentTypeApplicationJSON)
+		ctx.Request.SetBodyString(test.body)
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s. expected: %d, got: %d", test.body, test.status, code)
+		}
+
+		if test.field == "" {
+			continue
+		}
+
+		body := struct {
+			Details BindErrorDetails `json:"details"`
+		}{}
+
+		if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+			t.Fatal(err)
+		}
+
+		if body.Details.Field != test.field || body.Details.Expected != test.expected {
+			t.Errorf("unexpected details: %+v", body.Details)
+		}
+	}
+}
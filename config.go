@@ -40,6 +40,10 @@ func setDefaults(cfg Config) Config {
 		cfg.Network = DefaultNetwork
 	}
 
+	if cfg.JSONCodec == nil {
+		cfg.JSONCodec = defaultJSONCodec
+	}
+
 	if cfg.Logger == nil {
 		cfg.Logger = DefaultLogger()
 	}
@@ -48,6 +52,14 @@ func setDefaults(cfg Config) Config {
 		cfg.ReadTimeout = DefaultReadTimeout
 	}
 
+	if cfg.SSEKeepAliveInterval <= 0 {
+		cfg.SSEKeepAliveInterval = DefaultSSEKeepAliveInterval
+	}
+
+	if cfg.TimeoutStatusCode == 0 {
+		cfg.TimeoutStatusCode = DefaultTimeoutStatusCode
+	}
+
 	return cfg
 }
 
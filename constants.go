@@ -252,9 +252,17 @@ const (
 	ContentTypeApplicationJSON = "application/json"
 	ContentTypeApplicationXML  = "application/xml"
 	ContentTypeApplicationForm = "application/x-www-form-urlencoded"
+	ContentTypeApplicationYAML = "application/yaml"
+	ContentTypeMultipartForm   = "multipart/form-data"
 	ContentTypeTextXML         = "text/xml"
 	ContentTypeTextHTML        = "text/html"
 	ContentTypeTextPlain       = "text/plain"
+	ContentTypeTextEventStream = "text/event-stream"
+
+	ContentTypeApplicationMsgPack     = "application/msgpack"
+	ContentTypeApplicationNDJSON      = "application/x-ndjson"
+	ContentTypeApplicationProtobuf    = "application/x-protobuf"
+	ContentTypeApplicationProblemJSON = "application/problem+json"
 )
 
 const (
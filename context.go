@@ -2,10 +2,15 @@ package emir
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime/multipart"
 	"sync"
 
 	"github.com/valyala/fasthttp"
+	"github.com/vmihailenco/msgpack/v4"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 )
 
 var ctxPool sync.Pool
@@ -34,10 +39,18 @@ func releaseCtx(c *Context) {
 	c.RequestCtx = nil
 	c.next = false
 	c.err = false
+	c.index = 0
+	c.chainErr = nil
 	c.deferFuncs = nil
 	c.route = nil
 	c.emir = nil
 	c.stdURL = nil
+	if c.stdCancel != nil {
+		c.stdCancel()
+		c.stdCancel = nil
+	}
+	c.stdCtx = nil
+	c.snapshot = nil
 
 	ctxPool.Put(c)
 
@@ -59,10 +72,32 @@ func (c *Context) Emir() *Emir {
 	return c.emir
 }
 
+// URLFor generates the URL of the route that has given name.
+// Routes of the current host are preferred.
+// The result is a path when the route is on the current host, otherwise it is an absolute URL.
+func (c *Context) URLFor(name string, params ...interface{}) (string, error) {
+	host := ""
+	if c.route != nil {
+		host = c.route.host
+	}
+
+	route := c.emir.lookupRoute(name, host)
+	if route == nil {
+		return "", fmt.Errorf("emir: route %q not found", name)
+	}
+
+	path, err := route.URL(params...)
+	if err != nil || route.host == "" || route.host == host {
+		return path, err
+	}
+
+	return B2S(c.URI().Scheme()) + "://" + route.host + path, nil
+}
+
 // JSON sends a JSON response with given status code.
 // Status code is optional.
 func (c *Context) JSON(v interface{}, statusCode ...int) error {
-	bytes, err := json.Marshal(v)
+	bytes, err := c.jsonCodec().Marshal(v)
 	if err != nil {
 		return err
 	}
@@ -89,6 +124,89 @@ func (c *Context) JSONMarshaler(v json.Marshaler, statusCode ...int) error {
 	return nil
 }
 
+// XML sends a XML response with given status code.
+// Status code is optional.
+func (c *Context) XML(v interface{}, statusCode ...int) error {
+	bytes, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.setStatus(statusCode...)
+
+	c.SetBody(bytes)
+	c.SetContentType(ContentTypeApplicationXML)
+
+	return nil
+}
+
+// MsgPack sends a MessagePack response with given status code.
+// Status code is optional.
+func (c *Context) MsgPack(v interface{}, statusCode ...int) error {
+	bytes, err := msgpack.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.setStatus(statusCode...)
+
+	c.SetBody(bytes)
+	c.SetContentType(ContentTypeApplicationMsgPack)
+
+	return nil
+}
+
+// Protobuf sends a Protocol Buffers response with given status code.
+// Status code is optional.
+func (c *Context) Protobuf(v proto.Message, statusCode ...int) error {
+	bytes, err := proto.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.setStatus(statusCode...)
+
+	c.SetBody(bytes)
+	c.SetContentType(ContentTypeApplicationProtobuf)
+
+	return nil
+}
+
+// Render sends given value with given status code encoded by the renderer that the Accept header prefers.
+// Media ranges are ordered by their quality values, the renderers of the route are preferred over the renderers of Emir.
+// The first renderer is used if the request has no Accept header.
+// It returns an error with status 406 if none of the renderers is acceptable.
+// Status code is optional.
+func (c *Context) Render(v interface{}, statusCode ...int) error {
+	var renderers []Renderer
+	if c.route != nil {
+		renderers = append(renderers, c.route.Renderers...)
+	}
+
+	if c.emir != nil {
+		renderers = append(renderers, c.emir.renderers...)
+	}
+
+	c.Response.Header.Add(HeaderVary, HeaderAccept)
+
+	renderer := negotiateRenderer(B2S(c.Request.Header.Peek(HeaderAccept)), renderers)
+	if renderer == nil {
+		return NewBasicError(StatusNotAcceptable, "Not Acceptable")
+	}
+
+	bytes, err := renderer.Render(v)
+	if err != nil {
+		return err
+	}
+
+	c.setStatus(statusCode...)
+
+	c.SetBody(bytes)
+	c.SetContentType(renderer.ContentType())
+
+	return nil
+}
+
 // HTML sends a HTML response with given status code.
 // Status code is optional.
 func (c *Context) HTML(v string, statusCode ...int) error {
@@ -113,6 +231,10 @@ func (c *Context) HTMLBytes(v []byte, statusCode ...int) error {
 
 // Validate validates given value.
 func (c *Context) Validate(v interface{}) error {
+	if c.route == nil || c.route.Validator == nil {
+		return errValidatorNotRegistered
+	}
+
 	return c.route.Validator.Validate(v)
 }
 
@@ -121,6 +243,20 @@ func (c *Context) Bind(v interface{}) error {
 	return c.route.Binder.Bind(c, v)
 }
 
+// BindAndValidate binds the request into given value then validates it.
+func (c *Context) BindAndValidate(v interface{}) error {
+	if err := c.Bind(v); err != nil {
+		return err
+	}
+
+	return c.Validate(v)
+}
+
+// SaveFile saves given multipart file to the given path.
+func (c *Context) SaveFile(file *multipart.FileHeader, path string) error {
+	return fasthttp.SaveMultipartFile(file, path)
+}
+
 // RequestID returns the request id.
 func (c *Context) RequestID() []byte {
 	return c.ReqHeader().Peek(HeaderXRequestID)
@@ -137,6 +273,39 @@ func (c *Context) Next() error {
 	return nil
 }
 
+// Defer registers given function to be executed after the handler chain and the error handler,
+// even if a handler returns an error or panics. The functions are executed in LIFO order
+// and they can access the final response, including the status code.
+// The response is complete when they are executed, but fasthttp sends it to the client after them.
+func (c *Context) Defer(fn func()) {
+	c.deferFuncs = append(c.deferFuncs, fn)
+}
+
+// DeferErr is like Defer but given function receives the error that is returned by the handler chain.
+// The error is nil if the chain succeeded, a recovered panic is converted to an error.
+func (c *Context) DeferErr(fn func(error)) {
+	c.Defer(func() {
+		fn(c.chainErr)
+	})
+}
+
+// runDeferred executes the deferred functions in LIFO order, a panicking function doesn't stop the others
+func (c *Context) runDeferred() {
+	for i := len(c.deferFuncs) - 1; i >= 0; i-- {
+		c.runDeferredFunc(c.deferFuncs[i])
+	}
+}
+
+func (c *Context) runDeferredFunc(fn func()) {
+	defer func() {
+		if v := recover(); v != nil {
+			c.Logger().Error("panic recovered in deferred function", zap.Any("v", v))
+		}
+	}()
+
+	fn()
+}
+
 // PlainString sends a plain text response with given status code.
 // Status code is optional
 func (c *Context) PlainString(v string, statusCode ...int) error {
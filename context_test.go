@@ -0,0 +1,60 @@
+package emir
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"github.com/vmihailenco/msgpack/v4"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func Test_ResponseHelpers(t *testing.T) {
+	type user struct {
+		Name string `xml:"name" msgpack:"name"`
+	}
+
+	msgpackBody, err := msgpack.Marshal(user{Name: "emir"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	protobufBody, err := proto.Marshal(wrapperspb.String("emir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		handler     RequestHandler
+		status      int
+		contentType string
+		body        string
+	}{
+		{func(c *Context) error { return c.XML(user{Name: "emir"}, StatusCreated) }, StatusCreated, ContentTypeApplicationXML, "<user><name>emir</name></user>"},
+		{func(c *Context) error { return c.MsgPack(user{Name: "emir"}) }, StatusOK, ContentTypeApplicationMsgPack, string(msgpackBody)},
+		{func(c *Context) error { return c.Protobuf(wrapperspb.String("emir"), StatusAccepted) }, StatusAccepted, ContentTypeApplicationProtobuf, string(protobufBody)},
+	}
+
+	for _, test := range tests {
+		e := New(Config{})
+		e.GET("/", test.handler)
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI("/")
+
+		e.Handler()(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code. expected: %d, got: %d", test.status, code)
+		}
+
+		if contentType := string(ctx.Response.Header.ContentType()); contentType != test.contentType {
+			t.Errorf("unexpected content type. expected: %s, got: %s", test.contentType, contentType)
+		}
+
+		if body := string(ctx.Response.Body()); body != test.body {
+			t.Errorf("unexpected body for %s. expected: %q, got: %q", test.contentType, test.body, body)
+		}
+	}
+}
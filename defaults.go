@@ -1,6 +1,7 @@
 package emir
 
 import (
+	"errors"
 	"time"
 
 	"go.uber.org/zap"
@@ -18,6 +19,12 @@ const (
 
 	//DefaultReadTimeout is the default read timeout
 	DefaultReadTimeout = 20 * time.Second
+
+	//DefaultSSEKeepAliveInterval is the default interval of the keep-alive comments of the server-sent event streams
+	DefaultSSEKeepAliveInterval = 15 * time.Second
+
+	//DefaultTimeoutStatusCode is the default status code of the responses of the timed out routes
+	DefaultTimeoutStatusCode = StatusServiceUnavailable
 )
 
 // DefaultLogger creates a empty development logger
@@ -47,15 +54,35 @@ func DefaultPanicHandler(c *Context, v interface{}) {
 }
 
 // DefaultErrorHandler is the default error handler
+// ValidationErrors are rendered with status 422 and ProblemErrors are rendered as "application/problem+json".
+// Errors are matched with errors.As, so wrapped errors are supported.
+// Errors that aren't BasicErrors are converted by the error mappings of the route, the others are rendered as 500.
 func DefaultErrorHandler(ctx *Context, err error) {
-	basicError, ok := err.(*BasicError)
+	var problem *ProblemError
+	if errors.As(err, &problem) {
+		p := *problem
+		renderProblem(ctx, &p)
+
+		return
+	}
+
+	var validationErrors ValidationErrors
+	if errors.As(err, &validationErrors) {
+		if err := ctx.JSON(validationErrorBody{Message: "validation failed", Errors: validationErrors}, StatusUnprocessableEntity); err != nil {
+			ctx.SetStatusCode(500)
+		}
+
+		return
+	}
+
+	basicError, ok := resolveError(ctx, err)
 	if !ok {
 		ctx.SetStatusCode(500)
 
 		return
 	}
 
-	err = ctx.JSON(basicError)
+	err = ctx.JSON(basicError, basicError.StatusCode)
 	if err != nil {
 		ctx.SetStatusCode(500)
 
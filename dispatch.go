@@ -0,0 +1,203 @@
+package emir
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// compile flattens the middlewares of the router and the handlers of the route into the chain of the route.
+// The chain is immutable after compiling, so it is safe to share between concurrent requests.
+func (r *Route) compile(middlewares, afterMiddlewares []RequestHandler) {
+	chain := make([]RequestHandler, 0, len(middlewares)+len(r.Middlewares)+len(r.Handlers)+len(r.AfterMiddlewares)+len(afterMiddlewares))
+	chain = append(chain, middlewares...)
+	chain = append(chain, r.Middlewares...)
+	chain = append(chain, r.Handlers...)
+	chain = append(chain, r.AfterMiddlewares...)
+	chain = append(chain, afterMiddlewares...)
+
+	r.chain = chain
+}
+
+// dispatch returns the request handler that executes the compiled chain of the route
+func dispatch(e *Emir, route *Route) fasthttp.RequestHandler {
+	if route.RouteTimeout > 0 {
+		return dispatchTimeout(e, route)
+	}
+
+	return func(fctx *fasthttp.RequestCtx) {
+		ctx := acquireCtx(fctx)
+		ctx.route = route
+		ctx.emir = e
+
+		ctx.execute()
+	}
+}
+
+// dispatchTimeout returns the request handler that executes the chain of the route in another goroutine
+// and renders the timeout error when the chain exceeds the timeout of the route.
+// The context is released by the goroutine, fasthttp doesn't reuse the request ctx after TimeoutErrorWithResponse.
+func dispatchTimeout(e *Emir, route *Route) fasthttp.RequestHandler {
+	return func(fctx *fasthttp.RequestCtx) {
+		ctx := acquireCtx(fctx)
+		ctx.route = route
+		ctx.emir = e
+
+		// the request is copied before the chain can change it, the chain keeps the headers of the copy up to date
+		ctx.snapshot = &timeoutSnapshot{ctx: new(fasthttp.RequestCtx)}
+		fctx.Request.CopyTo(&ctx.snapshot.ctx.Request)
+		snapshot := ctx.snapshot
+
+		// the std context is created before the goroutine, so it can be canceled without racing with the chain
+		ctx.StdContext()
+		cancel := ctx.stdCancel
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ctx.execute()
+		}()
+
+		timer := time.NewTimer(route.RouteTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+
+		cancel()
+		timeoutCtx := snapshot.stop()
+
+		c := acquireCtx(timeoutCtx)
+		defer releaseCtx(c)
+		c.route = route
+		c.emir = e
+
+		status := e.cfg.TimeoutStatusCode
+		route.ErrorHandler(c, WrapBasicError(context.DeadlineExceeded, status, fasthttp.StatusMessage(status)))
+		fctx.TimeoutErrorWithResponse(&timeoutCtx.Response)
+	}
+}
+
+// timeoutSnapshot keeps the headers and the user values of a request that is executed with a timeout.
+// The chain updates it before every handler, so the timeout error is rendered with the headers and the user values
+// that are set before the timeout, such as CORS headers and the request id. It isn't updated after the timeout.
+type timeoutSnapshot struct {
+	mu       sync.Mutex
+	ctx      *fasthttp.RequestCtx
+	timedOut bool
+}
+
+// update copies the request headers, the response headers and the user values of given request ctx to the snapshot
+func (s *timeoutSnapshot) update(fctx *fasthttp.RequestCtx) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timedOut {
+		return
+	}
+
+	fctx.Request.Header.CopyTo(&s.ctx.Request.Header)
+	fctx.Response.Header.CopyTo(&s.ctx.Response.Header)
+	fctx.VisitUserValues(func(key []byte, value interface{}) {
+		s.ctx.SetUserValueBytes(key, value)
+	})
+}
+
+// stop stops the updates of the chain and returns the request ctx of the snapshot
+func (s *timeoutSnapshot) stop() *fasthttp.RequestCtx {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timedOut = true
+
+	return s.ctx
+}
+
+// execute runs the chain and the error handler, then it runs the deferred functions and releases the context
+func (c *Context) execute() {
+	defer func() {
+		// panics are recovered here instead of the router, so the deferred functions see the final response
+		if v := recover(); v != nil {
+			c.chainErr = panicError(v)
+			c.emir.cfg.PanicHandler(c, v)
+		}
+
+		c.runDeferred()
+		releaseCtx(c)
+	}()
+
+	if err := c.run(0); err != nil {
+		c.chainErr = err
+		c.route.ErrorHandler(c, handledError(err))
+	}
+}
+
+// handledError returns the error that is passed to the error handler.
+// Error handlers release the BasicErrors, so they get a copy and the error stays intact for DeferErr.
+func handledError(err error) error {
+	if basicError, ok := err.(*BasicError); ok {
+		handled := *basicError
+		return &handled
+	}
+
+	return err
+}
+
+// panicError converts the recovered value to an error
+func panicError(v interface{}) error {
+	if err, ok := v.(error); ok {
+		return fmt.Errorf("emir: panic recovered: %w", err)
+	}
+
+	return fmt.Errorf("emir: panic recovered: %v", v)
+}
+
+// run executes the chain of the route from given position until a handler returns an error
+// or doesn't call Next
+func (c *Context) run(index int) error {
+	if c.route == nil {
+		return nil
+	}
+
+	chain := c.route.chain
+	for c.index = index; c.index < len(chain); c.index++ {
+		if c.snapshot != nil {
+			c.snapshot.update(c.RequestCtx)
+		}
+
+		c.next = false
+		if err := chain[c.index](c); err != nil {
+			return err
+		}
+
+		if !c.next {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Wrap adapts given middleware to a RequestHandler, so it can be registered with Router.Use, Route.Use
+// and the other methods alongside the flat middlewares.
+// The next handler of the middleware executes the rest of the chain and returns its error instead of
+// passing it to the error handler. The chain stops if the middleware doesn't call the next handler.
+func Wrap(middleware Middleware) RequestHandler {
+	handler := middleware(func(c *Context) error {
+		return c.run(c.index + 1)
+	})
+
+	return func(c *Context) error {
+		err := handler(c)
+		// the rest of the chain is executed by the next handler
+		c.next = false
+
+		return err
+	}
+}
@@ -0,0 +1,352 @@
+package emir
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// newDispatchBenchmark returns the handler of an app that has the same routes with middlewares
+// on the default host and on a virtual host
+func newDispatchBenchmark() fasthttp.RequestHandler {
+	middleware := func(c *Context) error {
+		return c.Next()
+	}
+
+	handler := func(c *Context) error {
+		c.SetStatusCode(StatusNoContent)
+		return c.Next()
+	}
+
+	e := New(Config{})
+	vh := e.NewVirtualHost("example.com")
+	for _, r := range []Router{e, vh} {
+		r.Use(middleware, middleware)
+		r.After(middleware)
+
+		api := r.NewGroup("/api")
+		api.Use(middleware)
+		api.GET("/users", handler).Use(middleware).After(middleware)
+		api.GET("/users/{id}", handler).Use(middleware).After(middleware)
+	}
+
+	return e.Handler()
+}
+
+func benchmarkDispatch(b *testing.B, host, uri string) {
+	handler := newDispatchBenchmark()
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.Request.SetRequestURI(uri)
+	if host != "" {
+		ctx.Request.Header.SetHost(host)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		handler(ctx)
+	}
+}
+
+func BenchmarkRouter(b *testing.B) {
+	benchmarkDispatch(b, "", "/api/users")
+}
+
+func BenchmarkVirtualHost(b *testing.B) {
+	benchmarkDispatch(b, "example.com", "/api/users")
+}
+
+// fasthttp/router allocates the values of the path parameters
+func BenchmarkRouterParams(b *testing.B) {
+	benchmarkDispatch(b, "", "/api/users/1")
+}
+
+func BenchmarkVirtualHostParams(b *testing.B) {
+	benchmarkDispatch(b, "example.com", "/api/users/1")
+}
+
+func Test_DispatchAllocs(t *testing.T) {
+	handler := newDispatchBenchmark()
+
+	for _, host := range []string{"", "example.com"} {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI("/api/users")
+		if host != "" {
+			ctx.Request.Header.SetHost(host)
+		}
+
+		// warms up the pools
+		handler(ctx)
+
+		if allocs := testing.AllocsPerRun(100, func() { handler(ctx) }); allocs != 0 {
+			t.Errorf("unexpected allocations for host %q: %v", host, allocs)
+		}
+
+		if code := ctx.Response.StatusCode(); code != StatusNoContent {
+			t.Errorf("unexpected status code for host %q: %d", host, code)
+		}
+	}
+}
+
+func Test_ConcurrentChains(t *testing.T) {
+	e := New(Config{})
+
+	// the middlewares have extra capacity, appending to them must not share the backing array between the routes
+	middlewares := make([]RequestHandler, 0, 16)
+	middlewares = append(middlewares, func(c *Context) error {
+		return c.Next()
+	})
+	e.Use(middlewares...)
+
+	routes := 8
+	for i := 0; i < routes; i++ {
+		body := strconv.Itoa(i)
+		e.GET("/"+body, func(c *Context) error {
+			return c.PlainString(body)
+		})
+
+		group := e.NewGroup("/group" + body)
+		group.Use(func(c *Context) error {
+			return c.Next()
+		})
+		group.GET("/", func(c *Context) error {
+			return c.PlainString("group" + body)
+		})
+	}
+
+	handler := e.Handler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < routes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			expected := map[string]string{
+				"/" + strconv.Itoa(i):            strconv.Itoa(i),
+				"/group" + strconv.Itoa(i) + "/": "group" + strconv.Itoa(i),
+			}
+
+			for n := 0; n < 100; n++ {
+				for uri, body := range expected {
+					ctx := new(fasthttp.RequestCtx)
+					ctx.Request.Header.SetMethod(MethodGet)
+					ctx.Request.SetRequestURI(uri)
+
+					handler(ctx)
+
+					if got := string(ctx.Response.Body()); got != body {
+						t.Errorf("unexpected body for %s. expected: %s, got: %s", uri, body, got)
+						return
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func Test_Wrap(t *testing.T) {
+	var order []string
+	trace := func(name string) RequestHandler {
+		return func(c *Context) error {
+			order = append(order, name)
+			return c.Next()
+		}
+	}
+
+	wrap := func(name string) RequestHandler {
+		return Wrap(func(next RequestHandler) RequestHandler {
+			return func(c *Context) error {
+				order = append(order, name+" before")
+				err := next(c)
+				order = append(order, name+" after")
+
+				return err
+			}
+		})
+	}
+
+	recoverErrors := Wrap(func(next RequestHandler) RequestHandler {
+		return func(c *Context) error {
+			if err := next(c); err != nil {
+				return c.PlainString("recovered", StatusAccepted)
+			}
+
+			return nil
+		}
+	})
+
+	e := New(Config{})
+	e.Use(trace("flat"), wrap("outer"))
+	e.After(trace("after"))
+
+	e.GET("/", trace("handler")).Use(wrap("inner")).After(trace("route after"))
+	e.GET("/error", func(c *Context) error {
+		return NewBasicError(StatusBadRequest, "downstream")
+	}).Use(recoverErrors)
+	e.GET("/stop", trace("handler")).Use(Wrap(func(next RequestHandler) RequestHandler {
+		return func(c *Context) error {
+			return c.PlainString("short-circuited", StatusForbidden)
+		}
+	}))
+
+	handler := e.Handler()
+
+	tests := []struct {
+		uri    string
+		status int
+		body   string
+		order  []string
+	}{
+		{"/", StatusOK, "", []string{
+			"flat", "outer before", "inner before", "handler", "route after", "after", "inner after", "outer after",
+		}},
+		{"/error", StatusAccepted, "recovered", []string{"flat", "outer before", "outer after"}},
+		{"/stop", StatusForbidden, "short-circuited", []string{"flat", "outer before", "outer after"}},
+	}
+
+	for _, test := range tests {
+		order = nil
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI(test.uri)
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s. expected: %d, got: %d", test.uri, test.status, code)
+		}
+
+		if body := string(ctx.Response.Body()); body != test.body {
+			t.Errorf("unexpected body for %s. expected: %q, got: %q", test.uri, test.body, body)
+		}
+
+		if strings.Join(order, ",") != strings.Join(test.order, ",") {
+			t.Errorf("unexpected execution order for %s. expected: %v, got: %v", test.uri, test.order, order)
+		}
+	}
+}
+
+func Test_Defer(t *testing.T) {
+	var calls []string
+	record := func(c *Context, name string) {
+		c.Defer(func() {
+			calls = append(calls, name+":"+strconv.Itoa(c.Response.StatusCode()))
+		})
+	}
+
+	recordErr := func(c *Context) {
+		c.DeferErr(func(err error) {
+			if err == nil {
+				calls = append(calls, "err:nil")
+				return
+			}
+			calls = append(calls, "err:"+err.Error())
+		})
+	}
+
+	e := New(Config{Logger: zap.NewNop()})
+	e.Use(func(c *Context) error {
+		record(c, "middleware")
+		recordErr(c)
+		return c.Next()
+	})
+
+	e.GET("/ok", func(c *Context) error {
+		record(c, "handler")
+		return c.PlainString("ok", StatusCreated)
+	})
+	e.GET("/error", func(c *Context) error {
+		record(c, "handler")
+		return NewBasicError(StatusConflict, "conflict")
+	})
+	e.GET("/panic", func(c *Context) error {
+		c.Defer(func() {
+			panic("deferred panic")
+		})
+		record(c, "handler")
+		panic("handler panic")
+	})
+
+	handler := e.Handler()
+
+	tests := []struct {
+		uri    string
+		status int
+		calls  []string
+	}{
+		{"/ok", StatusCreated, []string{"handler:201", "err:nil", "middleware:201"}},
+		{"/error", StatusConflict, []string{"handler:409", "err:conflict", "middleware:409"}},
+		{"/panic", StatusInternalServerError, []string{"handler:500", "err:emir: panic recovered: handler panic", "middleware:500"}},
+	}
+
+	for _, test := range tests {
+		calls = nil
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI(test.uri)
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s. expected: %d, got: %d", test.uri, test.status, code)
+		}
+
+		if strings.Join(calls, ",") != strings.Join(test.calls, ",") {
+			t.Errorf("unexpected deferred calls for %s. expected: %v, got: %v", test.uri, test.calls, calls)
+		}
+	}
+}
+
+func Test_DeferErrReleasedError(t *testing.T) {
+	for _, errorHandler := range []ErrorHandler{DefaultErrorHandler, ProblemErrorHandler} {
+		var (
+			deferredErr error
+			basicError  *BasicError
+			code        interface{}
+		)
+
+		e := New(Config{ErrorHandler: errorHandler})
+		e.GET("/", func(c *Context) error {
+			c.DeferErr(func(err error) {
+				deferredErr = err
+				if errors.As(err, &basicError) {
+					code = basicError.ErrorCode
+				}
+			})
+
+			return WrapBasicError(sql.ErrNoRows, StatusNotFound, "not found", "missing_row")
+		})
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI("/")
+
+		e.Handler()(ctx)
+
+		if code := ctx.Response.StatusCode(); code != StatusNotFound {
+			t.Errorf("unexpected status code: %d", code)
+		}
+
+		if !errors.Is(deferredErr, sql.ErrNoRows) {
+			t.Errorf("cause of the deferred error is lost: %#v", deferredErr)
+		}
+
+		if basicError == nil || code != "missing_row" {
+			t.Errorf("code of the deferred error is lost: %#v", basicError)
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package emir
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 
 	"github.com/valyala/fasthttp"
 )
@@ -15,15 +20,27 @@ func New(cfg Config) *Emir {
 	frouter := newRouter(cfg)
 	fserver := fasthttpServer(cfg)
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	emir := &Emir{
 		server:       fserver,
+		ctx:          ctx,
+		cancel:       cancel,
 		fastrouter:   frouter,
 		errorHandler: cfg.ErrorHandler,
+		renderers:    defaultRenderers(cfg.JSONCodec),
 		cfg:          cfg,
 		Logger:       cfg.Logger,
 	}
 
-	emir.Router = &router{Binder: &DefaultBinder{}, emir: emir, errorHandler: cfg.ErrorHandler, Group: frouter.Group("")}
+	emir.Router = &router{
+		Binder:        &DefaultBinder{},
+		Validator:     &DefaultValidator{},
+		emir:          emir,
+		errorHandler:  cfg.ErrorHandler,
+		errorMappings: newErrorRegistry(nil),
+		Group:         frouter.Group(""),
+	}
 	return emir
 }
 
@@ -35,21 +52,129 @@ func (e *Emir) NewVirtualHost(hostname string) Router {
 
 	frouter := newRouter(e.cfg)
 	v := &virtualHost{
-		emir:         e,
-		errorHandler: e.errorHandler,
-		Router:       frouter,
+		emir:          e,
+		hostname:      hostname,
+		errorHandler:  e.errorHandler,
+		Binder:        &DefaultBinder{},
+		Validator:     &DefaultValidator{},
+		Router:        frouter,
+		errorMappings: newErrorRegistry(e.Router.(*router).errorMappings),
 	}
 	e.hosts[hostname] = v
 	return v
 }
 
+// Render registers given renderers to be used by Context.Render.
+// JSON, XML, MessagePack and YAML renderers are registered by default,
+// a renderer replaces the registered one that has the same content type.
+func (e *Emir) Render(renderers ...Renderer) {
+	e.renderers = registerRenderers(e.renderers, renderers...)
+}
+
+// URL generates the URL of the route that has given name.
+// Given params fill the path parameters of the route by order,
+// the remaining params are added to query string as key-value pairs.
+// URLs of the virtual host routes are absolute.
+func (e *Emir) URL(name string, params ...interface{}) (string, error) {
+	route := e.lookupRoute(name, "")
+	if route == nil {
+		return "", fmt.Errorf("emir: route %q not found", name)
+	}
+
+	path, err := route.URL(params...)
+	if err != nil {
+		return "", err
+	}
+
+	if route.host == "" {
+		return path, nil
+	}
+
+	schema := "http://"
+	if e.cfg.TLS {
+		schema = "https://"
+	}
+
+	return schema + route.host + path, nil
+}
+
+// Routes returns all registered routes.
+// Routes of the default host come first, virtual host routes follow them ordered by hostname.
+func (e *Emir) Routes() []RouteInfo {
+	routes := e.Router.(*router).routeInfos()
+	for _, hostname := range e.hostnames() {
+		routes = append(routes, e.hosts[hostname].routeInfos()...)
+	}
+
+	return routes
+}
+
+// lookupRoute finds the route by name.
+// Routes of the given host are preferred, the default host is searched next and virtual hosts are searched last.
+func (e *Emir) lookupRoute(name, host string) *Route {
+	if vhost := e.hosts[host]; vhost != nil {
+		if route := findRoute(vhost.registeredRoutes(), name); route != nil {
+			return route
+		}
+	}
+
+	if route := findRoute(e.Router.(*router).registeredRoutes(), name); route != nil {
+		return route
+	}
+
+	for _, hostname := range e.hostnames() {
+		if route := findRoute(e.hosts[hostname].registeredRoutes(), name); route != nil {
+			return route
+		}
+	}
+
+	return nil
+}
+
+func (e *Emir) hostnames() []string {
+	hostnames := make([]string, 0, len(e.hosts))
+	for hostname := range e.hosts {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	return hostnames
+}
+
+func findRoute(routes []*Route, name string) *Route {
+	for _, route := range routes {
+		if route.RouteName == name {
+			return route
+		}
+	}
+
+	return nil
+}
+
 // Handler returns router's request handler.
+// The handler is built on the first call and the same handler is returned after it,
+// routes that are registered after the first call aren't served.
 func (e *Emir) Handler() fasthttp.RequestHandler {
+	e.handlerOnce.Do(func() {
+		e.handler = e.buildHandler()
+	})
+
+	return e.handler
+}
+
+// buildHandler registers the routes to the routers and returns the request handler of the hosts
+func (e *Emir) buildHandler() fasthttp.RequestHandler {
 	e.Router.Handler()
+	e.fastrouter.NotFound = spaNotFound(e.Router.(*router).registeredFallbacks(), e.fastrouter.NotFound)
+
+	hosts := make(map[string]fasthttp.RequestHandler, len(e.hosts))
+	for hostname, vhost := range e.hosts {
+		hosts[hostname] = vhost.Handler()
+	}
+
 	handler := func(ctx *fasthttp.RequestCtx) {
-		vhost := e.hosts[B2S(ctx.Host())]
-		if vhost != nil {
-			vhost.Handler()(ctx)
+		if vhostHandler := hosts[B2S(ctx.Host())]; vhostHandler != nil {
+			vhostHandler(ctx)
 			return
 		}
 
@@ -110,6 +235,10 @@ func (e *Emir) Serve(ln net.Listener) error {
 		schema = "https://"
 	}
 
+	if e.cfg.LogRoutes {
+		e.Logger.Info("Registered routes\n" + routeTable(e.Routes()))
+	}
+
 	e.Logger.Info("Listening on " + schema + e.cfg.Addr)
 	e.server.Handler = e.Handler()
 	if e.cfg.TLS {
@@ -120,6 +249,28 @@ func (e *Emir) Serve(ln net.Listener) error {
 }
 
 // Shutdown shuts the server
+// Contexts returned by Context.StdContext are canceled before waiting for the open connections.
 func (e *Emir) Shutdown() error {
+	e.cancel()
+
 	return e.server.Shutdown()
 }
+
+// routeTable formats given routes as a table
+func routeTable(routes []RouteInfo) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "METHOD\tPATH\tHOST\tNAME\tMIDDLEWARES")
+	for _, route := range routes {
+		host := route.Host
+		if host == "" {
+			host = "*"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", route.Method, route.Path, host, route.Name, route.Middlewares)
+	}
+	w.Flush()
+
+	return b.String()
+}
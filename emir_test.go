@@ -129,3 +129,163 @@ func Test_VirtualHosts(t *testing.T) {
 		t.Error("handler hasn't executed")
 	}
 }
+
+func Test_MethodShortcuts(t *testing.T) {
+	e := New(Config{})
+	vh := e.NewVirtualHost("example.com")
+
+	for _, r := range []Router{e, vh} {
+		respond := func(body string) RequestHandler {
+			return func(c *Context) error {
+				return c.PlainString(body)
+			}
+		}
+
+		r.OPTIONS("/cors", respond("options"))
+		r.CONNECT("/proxy", respond("connect"))
+		r.GET("/any", respond("get"))
+		r.Any("/any", respond("any"))
+
+		routes := r.Match([]string{MethodPut, MethodPatch}, "/match", respond("match"))
+		if len(routes) != 2 || routes[0].Method != MethodPut || routes[1].Method != MethodPatch {
+			t.Errorf("unexpected routes: %v", routes)
+		}
+	}
+
+	handler := e.Handler()
+
+	tests := []struct {
+		method string
+		uri    string
+		status int
+		body   string
+	}{
+		{MethodOptions, "/cors", StatusOK, "options"},
+		{MethodConnect, "/proxy", StatusOK, "connect"},
+		{MethodGet, "/any", StatusOK, "get"},
+		{MethodPost, "/any", StatusOK, "any"},
+		{MethodDelete, "/any", StatusOK, "any"},
+		{MethodPut, "/match", StatusOK, "match"},
+		{MethodPatch, "/match", StatusOK, "match"},
+		{MethodPost, "/match", StatusNotFound, "Not Found"},
+	}
+
+	for _, host := range []string{"", "example.com"} {
+		for _, test := range tests {
+			ctx := new(fasthttp.RequestCtx)
+			ctx.Request.Header.SetMethod(test.method)
+			ctx.Request.SetRequestURI(test.uri)
+			if host != "" {
+				ctx.Request.Header.SetHost(host)
+			}
+
+			handler(ctx)
+
+			if code := ctx.Response.StatusCode(); code != test.status {
+				t.Errorf("unexpected status code for %s %s%s. expected: %d, got: %d", test.method, host, test.uri, test.status, code)
+			}
+
+			if body := string(ctx.Response.Body()); body != test.body {
+				t.Errorf("unexpected body for %s %s%s. expected: %q, got: %q", test.method, host, test.uri, test.body, body)
+			}
+		}
+	}
+}
+
+func Test_URL(t *testing.T) {
+	e := New(Config{})
+	handler := func(c *Context) error { return nil }
+
+	e.GET("/users/{id}", handler).Name("user")
+	e.NewGroup("/api").NewGroup("/v1").GET("/files/{filepath:*}", handler).Name("file")
+	e.GET("/posts/{slug?}", handler).Name("posts")
+	e.NewVirtualHost("example.com").NewGroup("/admin").GET("/{page}", handler).Name("admin")
+
+	tests := []struct {
+		name     string
+		params   []interface{}
+		expected string
+	}{
+		{"user", []interface{}{42}, "/users/42"},
+		{"user", []interface{}{"a b", "tab", "info"}, "/users/a%20b?tab=info"},
+		{"file", []interface{}{"css/main.css"}, "/api/v1/files/css/main.css"},
+		{"posts", nil, "/posts"},
+		{"posts", []interface{}{"hello"}, "/posts/hello"},
+		{"admin", []interface{}{"stats"}, "http://example.com/admin/stats"},
+	}
+
+	for _, test := range tests {
+		url, err := e.URL(test.name, test.params...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if url != test.expected {
+			t.Errorf("unexpected url. expected: %s, got: %s", test.expected, url)
+		}
+	}
+
+	if _, err := e.URL("user"); err == nil {
+		t.Error("expected missing parameter error")
+	}
+
+	if _, err := e.URL("user", 1, "key"); err == nil {
+		t.Error("expected key-value pair error")
+	}
+
+	if _, err := e.URL("not-found"); err == nil {
+		t.Error("expected route not found error")
+	}
+}
+
+func Test_URLFor(t *testing.T) {
+	var url string
+	e := New(Config{})
+	e.GET("/users/{id}", func(c *Context) error { return nil }).Name("user")
+
+	vh := e.NewVirtualHost("example.com")
+	vh.GET("/users/{id}", func(c *Context) error { return nil }).Name("user")
+	vh.GET("/", func(c *Context) error {
+		var err error
+		url, err = c.URLFor("user", 1)
+		return err
+	})
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.Request.Header.SetRequestURI("/")
+	ctx.Request.Header.SetHost("example.com")
+
+	e.Handler()(ctx)
+
+	if url != "/users/1" {
+		t.Errorf("unexpected url. expected: %s, got: %s", "/users/1", url)
+	}
+}
+
+func Test_Routes(t *testing.T) {
+	e := New(Config{})
+	handler := func(c *Context) error { return nil }
+
+	e.Use(handler)
+	e.GET("/", handler).Use(handler)
+	e.NewGroup("/api").POST("/users", handler).Name("createUser")
+	e.NewVirtualHost("example.com").DELETE("/{id}", handler).After(handler)
+
+	expected := []RouteInfo{
+		{Name: "/", Method: MethodGet, Path: "/", Middlewares: 2},
+		{Name: "createUser", Method: MethodPost, Path: "/api/users", Middlewares: 1},
+		{Name: "/{id}", Method: MethodDelete, Path: "/{id}", Host: "example.com", Middlewares: 1},
+	}
+
+	routes := e.Routes()
+	if len(routes) != len(expected) {
+		t.Fatalf("unexpected route count. expected: %d, got: %d", len(expected), len(routes))
+	}
+
+	for i := range expected {
+		if routes[i] != expected[i] {
+			t.Errorf("unexpected route. expected: %+v, got: %+v", expected[i], routes[i])
+		}
+	}
+}
@@ -1,9 +1,17 @@
 package emir
 
-import "sync"
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
 
 var errorPool sync.Pool
 
+var errValidatorNotRegistered = errors.New("emir: validator is not registered")
+
 // AcquireBasicError returns an error instance from context pool
 // The returned instance might be dirty
 // You should set all fields before using
@@ -24,6 +32,8 @@ func AcquireBasicError() *BasicError {
 // It is forbidden accessing to the released error instance
 func ReleaseBasicError(err *BasicError) {
 	err.ErrorCode = nil
+	err.Details = nil
+	err.Cause = nil
 	errorPool.Put(err)
 }
 
@@ -31,6 +41,11 @@ func (err *BasicError) Error() string {
 	return err.ErrorMessage
 }
 
+// Unwrap returns the underlying cause of the error
+func (err *BasicError) Unwrap() error {
+	return err.Cause
+}
+
 // NewBasicError returns an error instance that carries status, error message, and code
 //
 // Returned error should be released after used
@@ -47,3 +62,95 @@ func NewBasicError(status int, errorMessage string, errorCode ...interface{}) er
 
 	return err
 }
+
+// WrapBasicError returns an error instance that carries status, error message, code and the underlying cause
+//
+// The cause is not rendered, it is accessible via errors.Is, errors.As and errors.Unwrap
+func WrapBasicError(cause error, status int, errorMessage string, errorCode ...interface{}) error {
+	err := NewBasicError(status, errorMessage, errorCode...).(*BasicError)
+	err.Cause = cause
+
+	return err
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// errorMapping maps the errors that match the target to a status code and message
+type errorMapping struct {
+	target     error
+	targetType reflect.Type
+	status     int
+	message    string
+}
+
+// errorRegistry is the registry of the error mappings of a router.
+// Mappings of the parent registry are checked after the own mappings.
+type errorRegistry struct {
+	parent   *errorRegistry
+	mappings []errorMapping
+}
+
+func newErrorRegistry(parent *errorRegistry) *errorRegistry {
+	return &errorRegistry{parent: parent}
+}
+
+func (reg *errorRegistry) mapError(target error, status int, message []string) {
+	reg.mappings = append(reg.mappings, errorMapping{target: target, status: status, message: mappingMessage(status, message)})
+}
+
+func (reg *errorRegistry) mapErrorType(target interface{}, status int, message []string) {
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || (targetType.Kind() != reflect.Interface && !targetType.Implements(errorType)) {
+		panic("emir: error type must implement the error interface")
+	}
+
+	reg.mappings = append(reg.mappings, errorMapping{targetType: targetType, status: status, message: mappingMessage(status, message)})
+}
+
+// resolve returns the BasicError which the given error is mapped to.
+func (reg *errorRegistry) resolve(err error) (*BasicError, bool) {
+	for ; reg != nil; reg = reg.parent {
+		for _, mapping := range reg.mappings {
+			if mapping.target != nil && !errors.Is(err, mapping.target) {
+				continue
+			}
+
+			if mapping.targetType != nil && !errors.As(err, reflect.New(mapping.targetType).Interface()) {
+				continue
+			}
+
+			return &BasicError{StatusCode: mapping.status, ErrorMessage: mapping.message, Cause: err}, true
+		}
+	}
+
+	return nil, false
+}
+
+func mappingMessage(status int, message []string) string {
+	if len(message) != 0 {
+		return message[0]
+	}
+
+	return fasthttp.StatusMessage(status)
+}
+
+// resolveError converts given error to a BasicError.
+// BasicErrors in the error chain are preferred, the error mappings of the route are checked next.
+// The returned error may be released, wrapped BasicErrors are copied since they are still referenced by the wrapper.
+func resolveError(ctx *Context, err error) (*BasicError, bool) {
+	if basicError, ok := err.(*BasicError); ok {
+		return basicError, true
+	}
+
+	var basicError *BasicError
+	if errors.As(err, &basicError) {
+		wrapped := *basicError
+		return &wrapped, true
+	}
+
+	if ctx.route == nil {
+		return nil, false
+	}
+
+	return ctx.route.errorMappings.resolve(err)
+}
@@ -0,0 +1,124 @@
+package emir
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type conflictError struct {
+	resource string
+}
+
+func (err *conflictError) Error() string {
+	return err.resource + " already exists"
+}
+
+func Test_ErrorMappings(t *testing.T) {
+	e := New(Config{})
+	e.MapError(sql.ErrNoRows, StatusNotFound)
+	e.MapErrorType((*conflictError)(nil), StatusConflict, "conflict")
+
+	e.GET("/wrapped", func(c *Context) error {
+		return fmt.Errorf("handler: %w", NewBasicError(StatusForbidden, "forbidden", 1003))
+	})
+
+	e.GET("/sentinel", func(c *Context) error {
+		return fmt.Errorf("query user: %w", sql.ErrNoRows)
+	})
+
+	e.GET("/type", func(c *Context) error {
+		return fmt.Errorf("insert user: %w", &conflictError{resource: "user"})
+	})
+
+	e.GET("/cause", func(c *Context) error {
+		return WrapBasicError(sql.ErrConnDone, StatusServiceUnavailable, "database is unavailable")
+	})
+
+	e.GET("/unmapped", func(c *Context) error {
+		return errors.New("unmapped")
+	})
+
+	group := e.NewGroup("/group")
+	group.MapError(sql.ErrNoRows, StatusGone, "gone")
+	group.GET("/sentinel", func(c *Context) error {
+		return sql.ErrNoRows
+	})
+
+	group.GET("/type", func(c *Context) error {
+		return &conflictError{resource: "user"}
+	})
+
+	vh := e.NewVirtualHost("example.com")
+	vh.GET("/sentinel", func(c *Context) error {
+		return sql.ErrNoRows
+	})
+
+	handler := e.Handler()
+
+	tests := []struct {
+		host    string
+		uri     string
+		status  int
+		message string
+	}{
+		{"", "/wrapped", StatusForbidden, "forbidden"},
+		{"", "/sentinel", StatusNotFound, "Not Found"},
+		{"", "/type", StatusConflict, "conflict"},
+		{"", "/cause", StatusServiceUnavailable, "database is unavailable"},
+		{"", "/unmapped", StatusInternalServerError, ""},
+		{"", "/group/sentinel", StatusGone, "gone"},
+		{"", "/group/type", StatusConflict, "conflict"},
+		{"example.com", "/sentinel", StatusNotFound, "Not Found"},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI(test.uri)
+		if test.host != "" {
+			ctx.Request.Header.SetHost(test.host)
+		}
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s. expected: %d, got: %d", test.uri, test.status, code)
+		}
+
+		if test.message == "" {
+			continue
+		}
+
+		body := map[string]interface{}{}
+		if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+			t.Fatal(err)
+		}
+
+		if body["message"] != test.message {
+			t.Errorf("unexpected message for %s. expected: %s, got: %v", test.uri, test.message, body["message"])
+		}
+	}
+}
+
+func Test_WrapBasicError(t *testing.T) {
+	err := WrapBasicError(sql.ErrNoRows, StatusNotFound, "user not found")
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Error("cause isn't accessible via errors.Is")
+	}
+
+	body := map[string]interface{}{}
+	encoded, _ := json.Marshal(err)
+	if err := json.Unmarshal(encoded, &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := body["Cause"]; ok || body["message"] != "user not found" {
+		t.Errorf("unexpected body: %s", encoded)
+	}
+}
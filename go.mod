@@ -1,12 +1,16 @@
 module github.com/emirmuminoglu/emir
 
-go 1.15
+go 1.16
 
 require (
 	github.com/emirmuminoglu/jwt v1.0.0
 	github.com/fasthttp/router v1.3.6
+	github.com/fasthttp/websocket v1.4.2
 	github.com/google/uuid v1.2.0
 	github.com/pasztorpisti/qs v0.0.0-20171216220353-8d6c33ee906c
 	github.com/valyala/fasthttp v1.20.0
+	github.com/vmihailenco/msgpack/v4 v4.3.12
 	go.uber.org/zap v1.16.0
+	google.golang.org/protobuf v1.27.1
+	gopkg.in/yaml.v2 v2.4.0
 )
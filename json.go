@@ -0,0 +1,42 @@
+package emir
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// StdJSONCodec is the default implementation of the JSONCodec interface.
+// It uses encoding/json.
+type StdJSONCodec struct {
+}
+
+var defaultJSONCodec JSONCodec = &StdJSONCodec{}
+
+// Marshal implements the JSONCodec#Marshal function.
+func (*StdJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements the JSONCodec#Unmarshal function.
+func (*StdJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// NewEncoder implements the JSONCodec#NewEncoder function.
+func (*StdJSONCodec) NewEncoder(w io.Writer) JSONEncoder {
+	return json.NewEncoder(w)
+}
+
+// NewDecoder implements the JSONCodec#NewDecoder function.
+func (*StdJSONCodec) NewDecoder(r io.Reader) JSONDecoder {
+	return json.NewDecoder(r)
+}
+
+// jsonCodec returns the JSON codec of Emir, the default codec is returned if the context has no Emir instance.
+func (c *Context) jsonCodec() JSONCodec {
+	if c.emir == nil || c.emir.cfg.JSONCodec == nil {
+		return defaultJSONCodec
+	}
+
+	return c.emir.cfg.JSONCodec
+}
@@ -0,0 +1,89 @@
+package emir
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type countingJSONCodec struct {
+	StdJSONCodec
+	marshaled   int
+	unmarshaled int
+}
+
+func (codec *countingJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	codec.marshaled++
+	return codec.StdJSONCodec.Marshal(v)
+}
+
+func (codec *countingJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	codec.unmarshaled++
+	return codec.StdJSONCodec.Unmarshal(data, v)
+}
+
+func Test_JSONCodec(t *testing.T) {
+	codec := &countingJSONCodec{}
+	e := New(Config{JSONCodec: codec})
+
+	e.POST("/json", func(c *Context) error {
+		var body struct {
+			Name string `json:"name"`
+		}
+
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+
+		return c.JSON(body)
+	})
+
+	e.GET("/error", func(c *Context) error {
+		return NewBasicError(StatusBadRequest, "bad request")
+	})
+
+	e.GET("/problem", func(c *Context) error {
+		return NewProblemError(StatusConflict, "conflict")
+	})
+
+	e.GET("/render", func(c *Context) error {
+		return c.Render(map[string]string{"name": "emir"})
+	})
+
+	// the YAML document is converted from the JSON document
+	e.GET("/openapi.json", e.OpenAPIHandler(OpenAPIConfig{Title: "emir"}))
+
+	handler := e.Handler()
+
+	tests := []struct {
+		method      string
+		uri         string
+		body        string
+		marshaled   int
+		unmarshaled int
+	}{
+		{MethodPost, "/json", `{"name":"emir"}`, 1, 1},
+		{MethodGet, "/error", "", 1, 0},
+		{MethodGet, "/problem", "", 1, 0},
+		{MethodGet, "/render", "", 1, 0},
+		{MethodGet, "/openapi.json", "", 2, 0},
+	}
+
+	for _, test := range tests {
+		codec.marshaled, codec.unmarshaled = 0, 0
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(test.method)
+		ctx.Request.SetRequestURI(test.uri)
+		if test.body != "" {
+			ctx.Request.Header.SetContentType(ContentTypeApplicationJSON)
+			ctx.Request.SetBodyString(test.body)
+		}
+
+		handler(ctx)
+
+		if codec.marshaled != test.marshaled || codec.unmarshaled != test.unmarshaled {
+			t.Errorf("codec isn't used for %s. marshaled: %d, unmarshaled: %d", test.uri, codec.marshaled, codec.unmarshaled)
+		}
+	}
+}
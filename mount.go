@@ -0,0 +1,93 @@
+package emir
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	adaptor "github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// newMountHandler returns a request handler that strips the full prefix from the request URI and forwards the request
+// to given handler. The handler can be an *Emir, a fasthttp.RequestHandler, an http.Handler or a RequestHandler.
+// The request URI is restored after the handler returns, so the after middlewares see the original one.
+func newMountHandler(prefix string, handler interface{}) RequestHandler {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	var forward fasthttp.RequestHandler
+	switch h := handler.(type) {
+	case *Emir:
+		return mountApp(prefix, h)
+	case fasthttp.RequestHandler:
+		forward = h
+	case func(*fasthttp.RequestCtx):
+		forward = h
+	case http.Handler:
+		forward = adaptor.NewFastHTTPHandler(h)
+	case RequestHandler:
+		return mountRequestHandler(prefix, h)
+	case func(*Context) error:
+		return mountRequestHandler(prefix, h)
+	default:
+		panic(fmt.Sprintf("emir: unsupported mount handler type %T", handler))
+	}
+
+	return func(c *Context) error {
+		restore := stripPrefix(c.RequestCtx, prefix)
+		forward(c.RequestCtx)
+		restore()
+
+		return c.Next()
+	}
+}
+
+// mountApp forwards the request to the handler of given app.
+// The std context of the request is the parent of the std contexts of the app, so the requests of the app
+// are canceled by the shutdown of the app that serves them.
+func mountApp(prefix string, app *Emir) RequestHandler {
+	return func(c *Context) error {
+		c.SetUserValue(mountContextKey, c.StdContext())
+
+		restore := stripPrefix(c.RequestCtx, prefix)
+		// routes of the mounted app can be registered after mounting, so its handler is built on the first request
+		app.Handler()(c.RequestCtx)
+		restore()
+
+		return c.Next()
+	}
+}
+
+// mountRequestHandler executes the handler in the same context with the stripped request URI
+func mountRequestHandler(prefix string, handler RequestHandler) RequestHandler {
+	return func(c *Context) error {
+		restore := stripPrefix(c.RequestCtx, prefix)
+		defer restore()
+
+		return handler(c)
+	}
+}
+
+// stripPrefix removes the prefix from the path of the request URI by keeping the query string.
+// It returns a function that restores the original request URI.
+func stripPrefix(ctx *fasthttp.RequestCtx, prefix string) func() {
+	original := append([]byte(nil), ctx.Request.Header.RequestURI()...)
+
+	uri := ctx.URI()
+	requestPath := B2S(uri.PathOriginal())
+	if !hasPathPrefix(requestPath, prefix) {
+		// the original path has escaped characters in the prefix, the decoded one is used
+		requestPath = B2S(uri.Path())
+	}
+
+	strippedURI := "/" + strings.TrimPrefix(strings.TrimPrefix(requestPath, prefix), "/")
+	if query := uri.QueryString(); len(query) != 0 {
+		strippedURI += "?" + string(query)
+	}
+
+	ctx.Request.SetRequestURI(strippedURI)
+
+	return func() {
+		ctx.Request.SetRequestURIBytes(original)
+	}
+}
@@ -0,0 +1,183 @@
+package emir
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func Test_Mount(t *testing.T) {
+	e := New(Config{RedirectTrailingSlash: true})
+	e.Use(func(c *Context) error {
+		c.Response.Header.Set("X-Middleware", "true")
+		return c.Next()
+	})
+	e.After(func(c *Context) error {
+		c.Response.Header.Set("X-Original-Path", string(c.Path()))
+		return nil
+	})
+
+	sub := New(Config{HandleMethodNotAllowed: true})
+	e.Mount("/sub", sub)
+	// routes registered after mounting must be served too
+	sub.GET("/", func(c *Context) error {
+		return c.PlainString("sub index")
+	})
+	sub.GET("/users/{id}", func(c *Context) error {
+		return c.PlainString("sub user " + c.Param("id"))
+	})
+
+	legacy := e.NewGroup("/legacy")
+	legacy.Mount("/admin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery))
+	}))
+
+	e.Mount("/fast/", fasthttp.RequestHandler(func(ctx *fasthttp.RequestCtx) {
+		ctx.WriteString("fast " + string(ctx.Path()))
+	}))
+
+	e.Mount("/handler", func(c *Context) error {
+		return NewBasicError(StatusTeapot, string(c.Path()))
+	})
+
+	vh := e.NewVirtualHost("admin.example.com")
+	vh.Mount("/ui", sub)
+
+	handler := e.Handler()
+
+	tests := []struct {
+		host   string
+		method string
+		uri    string
+		status int
+		body   string
+	}{
+		{"", MethodGet, "/sub", StatusMovedPermanently, ""},
+		{"", MethodGet, "/sub/", StatusOK, "sub index"},
+		{"", MethodGet, "/sub/users/1", StatusOK, "sub user 1"},
+		{"", MethodPost, "/sub/users/1", StatusMethodNotAllowed, "Method Not Allowed"},
+		{"", MethodGet, "/sub/missing", StatusNotFound, "Not Found"},
+		{"", MethodDelete, "/legacy/admin/users?id=1", StatusOK, "DELETE /users?id=1"},
+		{"", MethodPut, "/legacy/admin/", StatusOK, "PUT /?"},
+		{"", MethodGet, "/fast/a/b", StatusOK, "fast /a/b"},
+		{"", MethodGet, "/handler/x", StatusTeapot, `"message":"/x"`},
+		{"admin.example.com", MethodGet, "/ui/users/2", StatusOK, "sub user 2"},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(test.method)
+		ctx.Request.SetRequestURI(test.uri)
+		if test.host != "" {
+			ctx.Request.Header.SetHost(test.host)
+		}
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s %s%s. expected: %d, got: %d", test.method, test.host, test.uri, test.status, code)
+		}
+
+		if body := string(ctx.Response.Body()); !strings.Contains(body, test.body) {
+			t.Errorf("unexpected body for %s %s%s. expected: %q, got: %q", test.method, test.host, test.uri, test.body, body)
+		}
+
+		if test.host == "" && test.status != StatusMovedPermanently && string(ctx.Response.Header.Peek("X-Middleware")) != "true" {
+			t.Errorf("middleware hasn't executed for %s", test.uri)
+		}
+
+		if test.host == "" && test.status == StatusOK && string(ctx.Response.Header.Peek("X-Original-Path")) != string(ctx.Path()) {
+			t.Errorf("request URI isn't restored for %s: %s", test.uri, ctx.Path())
+		}
+	}
+}
+
+func Test_MountServedApp(t *testing.T) {
+	sub := New(Config{})
+	sub.GET("/", func(c *Context) error {
+		return c.PlainString("sub index")
+	})
+
+	// the app is served on its own before it is mounted, like Serve does
+	served := sub.Handler()
+
+	e := New(Config{})
+	e.Mount("/sub", sub)
+	e.Mount("/again", sub)
+	handler := e.Handler()
+
+	for _, test := range []struct {
+		handler fasthttp.RequestHandler
+		uri     string
+	}{
+		{served, "/"},
+		{handler, "/sub/"},
+		{handler, "/again/"},
+		{e.Handler(), "/sub/"},
+	} {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI(test.uri)
+
+		test.handler(ctx)
+
+		if body := string(ctx.Response.Body()); body != "sub index" {
+			t.Errorf("unexpected body for %s: %q", test.uri, body)
+		}
+	}
+}
+
+func Test_MountStdContext(t *testing.T) {
+	started := make(chan struct{})
+	canceled := make(chan error, 1)
+
+	sub := New(Config{})
+	sub.GET("/wait", func(c *Context) error {
+		ctx := c.StdContext()
+		close(started)
+
+		select {
+		case <-ctx.Done():
+			canceled <- ctx.Err()
+		case <-time.After(time.Second):
+			canceled <- nil
+		}
+
+		return nil
+	})
+
+	e := New(Config{})
+	e.Mount("/sub", sub)
+	handler := e.Handler()
+
+	go func() {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI("/sub/wait")
+
+		handler(ctx)
+	}()
+
+	<-started
+	if err := e.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-canceled; err != context.Canceled {
+		t.Errorf("context of the mounted app isn't canceled by the shutdown: %v", err)
+	}
+}
+
+func Test_MountUnsupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("mounting an unsupported handler must panic")
+		}
+	}()
+
+	New(Config{}).Mount("/invalid", "handler")
+}
@@ -0,0 +1,584 @@
+package emir
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	fastrouter "github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"gopkg.in/yaml.v2"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification of generated documents
+const OpenAPIVersion = "3.0.3"
+
+type (
+	// OpenAPIConfig carries configuration for OpenAPI document generation
+	OpenAPIConfig struct {
+		Title       string
+		Description string
+		Version     string
+		// Servers are the URLs of the servers that serves the API
+		Servers []string
+		// Host is the virtual host whose routes will be documented.
+		// Routes of the default host are documented if it is empty.
+		Host string
+	}
+
+	// RouteDoc carries documentation of a route
+	RouteDoc struct {
+		Summary     string
+		Description string
+		Tags        []string
+		Deprecated  bool
+		// Request is a value of the type that the route binds.
+		// Fields with "path", "header", "cookie" and "qs" tags are documented as parameters,
+		// the others are documented as the request body.
+		Request interface{}
+		// Responses maps status codes to values of the response types.
+		// Nil values document responses without body.
+		Responses map[int]interface{}
+	}
+
+	// OpenAPIDocument is the root object of an OpenAPI document
+	OpenAPIDocument struct {
+		OpenAPI    string                                  `json:"openapi"`
+		Info       OpenAPIInfo                             `json:"info"`
+		Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+		Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+		Components *OpenAPIComponents                      `json:"components,omitempty"`
+
+		codec JSONCodec
+	}
+
+	// OpenAPIInfo is the metadata of the API
+	OpenAPIInfo struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	// OpenAPIServer represents a server that serves the API
+	OpenAPIServer struct {
+		URL string `json:"url"`
+	}
+
+	// OpenAPIComponents holds the reusable schemas
+	OpenAPIComponents struct {
+		Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+	}
+
+	// OpenAPIOperation describes an operation on a path
+	OpenAPIOperation struct {
+		OperationID string                      `json:"operationId,omitempty"`
+		Summary     string                      `json:"summary,omitempty"`
+		Description string                      `json:"description,omitempty"`
+		Tags        []string                    `json:"tags,omitempty"`
+		Deprecated  bool                        `json:"deprecated,omitempty"`
+		Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*OpenAPIResponse `json:"responses"`
+	}
+
+	// OpenAPIParameter describes a path, query, header or cookie parameter
+	OpenAPIParameter struct {
+		Name     string         `json:"name"`
+		In       string         `json:"in"`
+		Required bool           `json:"required,omitempty"`
+		Schema   *OpenAPISchema `json:"schema,omitempty"`
+	}
+
+	// OpenAPIRequestBody describes a request body
+	OpenAPIRequestBody struct {
+		Required bool                        `json:"required,omitempty"`
+		Content  map[string]OpenAPIMediaType `json:"content"`
+	}
+
+	// OpenAPIResponse describes a response
+	OpenAPIResponse struct {
+		Description string                      `json:"description"`
+		Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+	}
+
+	// OpenAPIMediaType carries the schema of a media type
+	OpenAPIMediaType struct {
+		Schema *OpenAPISchema `json:"schema,omitempty"`
+	}
+
+	// OpenAPISchema is a subset of the OpenAPI schema object
+	OpenAPISchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Pattern              string                    `json:"pattern,omitempty"`
+		Minimum              *float64                  `json:"minimum,omitempty"`
+		Items                *OpenAPISchema            `json:"items,omitempty"`
+		Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+		Required             []string                  `json:"required,omitempty"`
+		AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	}
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	bytesType    = reflect.TypeOf([]byte(nil))
+)
+
+// Doc sets the documentation of the route
+func (r *Route) Doc(doc RouteDoc) *Route {
+	r.RouteDoc = doc
+
+	return r
+}
+
+// OpenAPI generates an OpenAPI document from the registered routes
+func (e *Emir) OpenAPI(cfg OpenAPIConfig) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       cfg.Title,
+			Description: cfg.Description,
+			Version:     cfg.Version,
+		},
+		Paths: map[string]map[string]*OpenAPIOperation{},
+		codec: e.cfg.JSONCodec,
+	}
+
+	for _, server := range cfg.Servers {
+		doc.Servers = append(doc.Servers, OpenAPIServer{URL: server})
+	}
+
+	var routes []*Route
+	if cfg.Host == "" {
+		routes = e.Router.(*router).registeredRoutes()
+	} else if vhost := e.hosts[cfg.Host]; vhost != nil {
+		routes = vhost.registeredRoutes()
+	}
+
+	schemas := &openAPISchemas{named: map[reflect.Type]string{}, schemas: map[string]*OpenAPISchema{}}
+	for _, route := range routes {
+		if route.Method == fastrouter.MethodWild {
+			continue
+		}
+
+		for _, path := range openAPIPaths(route.fullPath) {
+			if doc.Paths[path.path] == nil {
+				doc.Paths[path.path] = map[string]*OpenAPIOperation{}
+			}
+
+			method := strings.ToLower(route.Method)
+			// routes that match the path are preferred over the SPA fallbacks
+			if _, ok := doc.Paths[path.path][method]; ok && route.fallback {
+				continue
+			}
+
+			doc.Paths[path.path][method] = schemas.operation(route, path.params)
+		}
+	}
+
+	if len(schemas.schemas) != 0 {
+		doc.Components = &OpenAPIComponents{Schemas: schemas.schemas}
+	}
+
+	return doc
+}
+
+// OpenAPIHandler returns a request handler that serves the OpenAPI document of the registered routes.
+// The document is served as YAML if the path ends with ".yaml" or ".yml", otherwise it is served as JSON.
+// The document is generated on the first request.
+func (e *Emir) OpenAPIHandler(cfg OpenAPIConfig) RequestHandler {
+	var (
+		once     sync.Once
+		jsonBody []byte
+		yamlBody []byte
+		err      error
+	)
+
+	return func(c *Context) error {
+		once.Do(func() {
+			doc := e.OpenAPI(cfg)
+			if jsonBody, err = doc.JSON(); err != nil {
+				return
+			}
+
+			yamlBody, err = doc.YAML()
+		})
+
+		if err != nil {
+			return err
+		}
+
+		path := B2S(c.Path())
+		if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+			c.SetBody(yamlBody)
+			c.SetContentType(ContentTypeApplicationYAML)
+
+			return nil
+		}
+
+		c.json(jsonBody)
+
+		return nil
+	}
+}
+
+// JSON encodes the document as JSON with the JSON codec of Emir
+func (doc *OpenAPIDocument) JSON() ([]byte, error) {
+	if doc.codec == nil {
+		return defaultJSONCodec.Marshal(doc)
+	}
+
+	return doc.codec.Marshal(doc)
+}
+
+// YAML encodes the document as YAML
+func (doc *OpenAPIDocument) YAML() ([]byte, error) {
+	body, err := doc.JSON()
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, decoding it into a MapSlice keeps the order of the keys
+	var v yaml.MapSlice
+	if err := yaml.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(v)
+}
+
+type openAPIPath struct {
+	path   string
+	params []pathParam
+}
+
+// openAPIPaths converts given fasthttp/router path to OpenAPI paths.
+// Paths with an optional parameter are documented with and without the parameter.
+func openAPIPaths(path string) []openAPIPath {
+	params := parsePathParams(path)
+	for _, param := range params {
+		if !param.Optional {
+			continue
+		}
+
+		without := strings.TrimSuffix(path[:param.start], "/") + path[param.end:]
+		if without == "" {
+			without = "/"
+		}
+
+		with := path[:param.start] + "{" + param.Name + "}" + path[param.end:]
+
+		return append(openAPIPaths(without), openAPIPaths(with)...)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, param := range params {
+		b.WriteString(path[last:param.start])
+		b.WriteString("{" + param.Name + "}")
+		last = param.end
+	}
+	b.WriteString(path[last:])
+
+	return []openAPIPath{{path: b.String(), params: params}}
+}
+
+type openAPISchemas struct {
+	named   map[reflect.Type]string
+	schemas map[string]*OpenAPISchema
+}
+
+func (s *openAPISchemas) operation(route *Route, params []pathParam) *OpenAPIOperation {
+	doc := route.RouteDoc
+	op := &OpenAPIOperation{
+		Summary:     doc.Summary,
+		Description: doc.Description,
+		Tags:        doc.Tags,
+		Deprecated:  doc.Deprecated,
+		Responses:   map[string]*OpenAPIResponse{},
+	}
+
+	// routes are named by their path by default which is not an unique operation id
+	if route.RouteName != route.Path {
+		op.OperationID = route.RouteName
+	}
+
+	for _, param := range params {
+		schema := &OpenAPISchema{Type: "string", Pattern: param.Pattern}
+		op.Parameters = append(op.Parameters, &OpenAPIParameter{Name: param.Name, In: "path", Required: true, Schema: schema})
+	}
+
+	if doc.Request != nil {
+		s.request(op, route.Method, reflect.TypeOf(doc.Request))
+	}
+
+	codes := make([]int, 0, len(doc.Responses))
+	for code := range doc.Responses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		response := &OpenAPIResponse{Description: fasthttp.StatusMessage(code)}
+		if v := doc.Responses[code]; v != nil {
+			response.Content = map[string]OpenAPIMediaType{
+				ContentTypeApplicationJSON: {Schema: s.schema(reflect.TypeOf(v))},
+			}
+		}
+
+		op.Responses[strconv.Itoa(code)] = response
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses[strconv.Itoa(StatusOK)] = &OpenAPIResponse{Description: fasthttp.StatusMessage(StatusOK)}
+	}
+
+	return op
+}
+
+// request documents the parameters and the body of given request type
+func (s *openAPISchemas) request(op *OpenAPIOperation, method string, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]OpenAPIMediaType{ContentTypeApplicationJSON: {Schema: s.schema(t)}},
+		}
+
+		return
+	}
+
+	body := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	visitFields(t, func(field reflect.StructField) {
+		if name := field.Tag.Get("path"); name != "" && name != "-" {
+			for _, param := range op.Parameters {
+				if param.In == "path" && param.Name == name {
+					schema := s.schema(field.Type)
+					schema.Pattern = param.Schema.Pattern
+					param.Schema = schema
+				}
+			}
+
+			return
+		}
+
+		for _, in := range [...]string{"header", "cookie"} {
+			if name := field.Tag.Get(in); name != "" && name != "-" {
+				op.Parameters = append(op.Parameters, &OpenAPIParameter{Name: name, In: in, Schema: s.schema(field.Type)})
+
+				return
+			}
+		}
+
+		if tag, ok := field.Tag.Lookup("qs"); ok {
+			name, options := splitTag(tag)
+			if name == "-" {
+				return
+			}
+
+			if name == "" {
+				name = snakeCase(field.Name)
+			}
+
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{
+				Name:     name,
+				In:       "query",
+				Required: hasOption(options, "req") || hasOption(field.Tag.Get("validate"), "required"),
+				Schema:   s.schema(field.Type),
+			})
+
+			return
+		}
+
+		if name, ok := jsonFieldName(field); ok {
+			body.addProperty(name, s.fieldSchema(field), field)
+		}
+	})
+
+	if len(body.Properties) != 0 && (method == MethodPost || method == MethodPut || method == MethodPatch) {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]OpenAPIMediaType{ContentTypeApplicationJSON: {Schema: body}},
+		}
+	}
+}
+
+// schema reflects the schema of given type as "encoding/json" encodes it
+func (s *openAPISchemas) schema(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case bytesType:
+		return &OpenAPISchema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		min := 0.0
+		return &OpenAPISchema{Type: "integer", Minimum: &min}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &OpenAPISchema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		return s.structSchema(t)
+	}
+
+	return &OpenAPISchema{}
+}
+
+// structSchema returns a reference to the component schema of given named struct type.
+// Anonymous structs are inlined.
+func (s *openAPISchemas) structSchema(t reflect.Type) *OpenAPISchema {
+	name, ok := s.named[t]
+	if ok {
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	}
+
+	schema := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	if t.Name() != "" {
+		name = t.Name()
+		for i := 2; s.schemas[name] != nil; i++ {
+			name = t.Name() + strconv.Itoa(i)
+		}
+
+		// registers the schema before visiting the fields to support recursive types
+		s.named[t] = name
+		s.schemas[name] = schema
+	}
+
+	visitFields(t, func(field reflect.StructField) {
+		if name, ok := jsonFieldName(field); ok {
+			schema.addProperty(name, s.fieldSchema(field), field)
+		}
+	})
+
+	if name == "" {
+		return schema
+	}
+
+	return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// addProperty adds given property to the object schema.
+// The property is required if the field has the "required" validation rule.
+func (schema *OpenAPISchema) addProperty(name string, property *OpenAPISchema, field reflect.StructField) {
+	schema.Properties[name] = property
+	if hasOption(field.Tag.Get("validate"), "required") {
+		schema.Required = append(schema.Required, name)
+	}
+}
+
+func (s *openAPISchemas) fieldSchema(field reflect.StructField) *OpenAPISchema {
+	if _, options := splitTag(field.Tag.Get("json")); hasOption(options, "string") {
+		return &OpenAPISchema{Type: "string"}
+	}
+
+	return s.schema(field.Type)
+}
+
+// visitFields visits exported fields of given struct type.
+// Fields of the embedded structs are visited as they are fields of the outer struct.
+func visitFields(t reflect.Type, visit func(reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+				visitFields(ft, visit)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		visit(field)
+	}
+}
+
+// jsonFieldName returns the name of the field as "encoding/json" encodes it
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	name, _ := splitTag(field.Tag.Get("json"))
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	}
+
+	return name, true
+}
+
+// splitTag splits given struct tag into its name and options
+func splitTag(tag string) (string, string) {
+	if idx := strings.IndexByte(tag, ','); idx != -1 {
+		return tag[:idx], tag[idx+1:]
+	}
+
+	return tag, ""
+}
+
+// hasOption reports whether given comma separated tag options contain the option
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// snakeCase converts CamelCase names to snake_case names as the qs package does
+func snakeCase(s string) string {
+	in := []rune(s)
+	isLower := func(idx int) bool {
+		return idx >= 0 && idx < len(in) && unicode.IsLower(in[idx])
+	}
+
+	out := make([]rune, 0, len(in)+len(in)/2)
+	for i, r := range in {
+		if unicode.IsUpper(r) {
+			r = unicode.ToLower(r)
+			if i > 0 && in[i-1] != '_' && (isLower(i-1) || isLower(i+1)) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, r)
+	}
+
+	return string(out)
+}
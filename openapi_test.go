@@ -0,0 +1,113 @@
+package emir
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type openAPIUser struct {
+	ID      int           `json:"id"`
+	Name    string        `json:"name"`
+	Friends []openAPIUser `json:"friends,omitempty"`
+	secret  string
+}
+
+type openAPIUpdateUser struct {
+	Notify bool   `qs:"notify,req"`
+	Name   string `json:"name"`
+	Ignore string `json:"-"`
+}
+
+func Test_OpenAPI(t *testing.T) {
+	e := New(Config{})
+	handler := func(c *Context) error { return nil }
+
+	e.GET("/users/{id:[0-9]+}", handler).Name("getUser").Doc(RouteDoc{
+		Summary:   "Get a user",
+		Tags:      []string{"users"},
+		Responses: map[int]interface{}{StatusOK: openAPIUser{}, StatusNotFound: nil},
+	})
+	e.NewGroup("/api").PUT("/users/{id}", handler).Doc(RouteDoc{Request: &openAPIUpdateUser{}})
+	e.GET("/posts/{slug?}", handler)
+
+	doc := e.OpenAPI(OpenAPIConfig{Title: "test", Version: "1.0.0"})
+
+	get := doc.Paths["/users/{id}"]["get"]
+	if get == nil {
+		t.Fatal("get operation is missing")
+	}
+
+	if get.OperationID != "getUser" || get.Summary != "Get a user" {
+		t.Errorf("unexpected operation: %+v", get)
+	}
+
+	if len(get.Parameters) != 1 || get.Parameters[0].In != "path" || get.Parameters[0].Schema.Pattern != "[0-9]+" {
+		t.Errorf("unexpected path parameters: %+v", get.Parameters)
+	}
+
+	if ref := get.Responses["200"].Content[ContentTypeApplicationJSON].Schema.Ref; ref != "#/components/schemas/openAPIUser" {
+		t.Errorf("unexpected response schema ref: %s", ref)
+	}
+
+	user := doc.Components.Schemas["openAPIUser"]
+	if len(user.Properties) != 3 || user.Properties["friends"].Items.Ref != "#/components/schemas/openAPIUser" {
+		t.Errorf("unexpected user schema: %+v", user)
+	}
+
+	put := doc.Paths["/api/users/{id}"]["put"]
+	if put == nil {
+		t.Fatal("put operation is missing")
+	}
+
+	if put.OperationID != "" {
+		t.Errorf("unexpected operation id: %s", put.OperationID)
+	}
+
+	if len(put.Parameters) != 2 || put.Parameters[1].Name != "notify" || !put.Parameters[1].Required {
+		t.Errorf("unexpected parameters: %+v", put.Parameters)
+	}
+
+	body := put.RequestBody.Content[ContentTypeApplicationJSON].Schema
+	if len(body.Properties) != 1 || body.Properties["name"].Type != "string" {
+		t.Errorf("unexpected request body: %+v", body)
+	}
+
+	if doc.Paths["/posts"] == nil || doc.Paths["/posts/{slug}"] == nil {
+		t.Error("optional path parameter variants are missing")
+	}
+}
+
+func Test_OpenAPIHandler(t *testing.T) {
+	e := New(Config{})
+	handler := e.OpenAPIHandler(OpenAPIConfig{Title: "test", Version: "1.0.0"})
+	e.GET("/openapi.json", handler)
+	e.GET("/openapi.yaml", handler)
+
+	h := e.Handler()
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.Request.Header.SetRequestURI("/openapi.json")
+	h(ctx)
+
+	doc := new(OpenAPIDocument)
+	if err := json.Unmarshal(ctx.Response.Body(), doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != OpenAPIVersion || len(doc.Paths) != 2 {
+		t.Errorf("unexpected document: %s", ctx.Response.Body())
+	}
+
+	ctx = new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.Request.Header.SetRequestURI("/openapi.yaml")
+	h(ctx)
+
+	if !bytes.HasPrefix(ctx.Response.Body(), []byte("openapi: 3.0.3")) {
+		t.Errorf("unexpected yaml document: %s", ctx.Response.Body())
+	}
+}
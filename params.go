@@ -0,0 +1,261 @@
+package emir
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Param returns the path parameter by name.
+func (c *Context) Param(name string) string {
+	switch v := c.UserValue(name).(type) {
+	case string:
+		return v
+	case []byte:
+		return B2S(v)
+	}
+
+	return ""
+}
+
+// ParamInt returns the path parameter by name as int.
+// It returns an error with status 400 if the parameter is missing or malformed.
+func (c *Context) ParamInt(name string) (int, error) {
+	v, err := c.ParamInt64(name)
+
+	return int(v), err
+}
+
+// ParamInt64 returns the path parameter by name as int64.
+// It returns an error with status 400 if the parameter is missing or malformed.
+func (c *Context) ParamInt64(name string) (int64, error) {
+	param, err := c.requiredParam(name)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, invalidParamError("path", name)
+	}
+
+	return v, nil
+}
+
+// ParamUint64 returns the path parameter by name as uint64.
+// It returns an error with status 400 if the parameter is missing or malformed.
+func (c *Context) ParamUint64(name string) (uint64, error) {
+	param, err := c.requiredParam(name)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, invalidParamError("path", name)
+	}
+
+	return v, nil
+}
+
+// ParamUUID returns the path parameter by name as UUID.
+// It returns an error with status 400 if the parameter is missing or malformed.
+func (c *Context) ParamUUID(name string) (uuid.UUID, error) {
+	param, err := c.requiredParam(name)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	v, err := uuid.Parse(param)
+	if err != nil {
+		return uuid.Nil, invalidParamError("path", name)
+	}
+
+	return v, nil
+}
+
+// QueryAll returns all values of the query parameter by name.
+func (c *Context) QueryAll(key string) []string {
+	var values []string
+	for _, v := range c.QueryArgs().PeekMulti(key) {
+		values = append(values, string(v))
+	}
+
+	return values
+}
+
+// QueryDefault returns the query parameter by name.
+// Default value is returned if the parameter is missing.
+func (c *Context) QueryDefault(key string, defaultValue string) string {
+	if !c.QueryArgs().Has(key) {
+		return defaultValue
+	}
+
+	return c.Query(key)
+}
+
+// QueryInt returns the query parameter by name as int.
+// Default value is optional, it is returned if the parameter is missing.
+// It returns an error with status 400 if the parameter is malformed.
+func (c *Context) QueryInt(key string, defaultValue ...int) (int, error) {
+	if !c.QueryArgs().Has(key) {
+		if len(defaultValue) != 0 {
+			return defaultValue[0], nil
+		}
+
+		return 0, nil
+	}
+
+	v, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return 0, invalidParamError("query", key)
+	}
+
+	return v, nil
+}
+
+// QueryInt64 returns the query parameter by name as int64.
+// Default value is optional, it is returned if the parameter is missing.
+// It returns an error with status 400 if the parameter is malformed.
+func (c *Context) QueryInt64(key string, defaultValue ...int64) (int64, error) {
+	if !c.QueryArgs().Has(key) {
+		if len(defaultValue) != 0 {
+			return defaultValue[0], nil
+		}
+
+		return 0, nil
+	}
+
+	v, err := strconv.ParseInt(c.Query(key), 10, 64)
+	if err != nil {
+		return 0, invalidParamError("query", key)
+	}
+
+	return v, nil
+}
+
+// QueryFloat64 returns the query parameter by name as float64.
+// Default value is optional, it is returned if the parameter is missing.
+// It returns an error with status 400 if the parameter is malformed.
+func (c *Context) QueryFloat64(key string, defaultValue ...float64) (float64, error) {
+	if !c.QueryArgs().Has(key) {
+		if len(defaultValue) != 0 {
+			return defaultValue[0], nil
+		}
+
+		return 0, nil
+	}
+
+	v, err := strconv.ParseFloat(c.Query(key), 64)
+	if err != nil {
+		return 0, invalidParamError("query", key)
+	}
+
+	return v, nil
+}
+
+// QueryBool returns the query parameter by name as bool.
+// Default value is optional, it is returned if the parameter is missing.
+// A parameter without value like "?verbose" is true.
+// It returns an error with status 400 if the parameter is malformed.
+func (c *Context) QueryBool(key string, defaultValue ...bool) (bool, error) {
+	if !c.QueryArgs().Has(key) {
+		if len(defaultValue) != 0 {
+			return defaultValue[0], nil
+		}
+
+		return false, nil
+	}
+
+	query := c.Query(key)
+	if query == "" {
+		return true, nil
+	}
+
+	v, err := strconv.ParseBool(query)
+	if err != nil {
+		return false, invalidParamError("query", key)
+	}
+
+	return v, nil
+}
+
+// QueryDuration returns the query parameter by name as time.Duration.
+// The parameter must be in the format time.ParseDuration accepts.
+// Default value is optional, it is returned if the parameter is missing.
+// It returns an error with status 400 if the parameter is malformed.
+func (c *Context) QueryDuration(key string, defaultValue ...time.Duration) (time.Duration, error) {
+	if !c.QueryArgs().Has(key) {
+		if len(defaultValue) != 0 {
+			return defaultValue[0], nil
+		}
+
+		return 0, nil
+	}
+
+	v, err := time.ParseDuration(c.Query(key))
+	if err != nil {
+		return 0, invalidParamError("query", key)
+	}
+
+	return v, nil
+}
+
+// QueryTime returns the query parameter by name as time.Time parsed with given layout.
+// Layout is time.RFC3339 if it is empty.
+// Default value is optional, it is returned if the parameter is missing.
+// It returns an error with status 400 if the parameter is malformed.
+func (c *Context) QueryTime(key string, layout string, defaultValue ...time.Time) (time.Time, error) {
+	if !c.QueryArgs().Has(key) {
+		if len(defaultValue) != 0 {
+			return defaultValue[0], nil
+		}
+
+		return time.Time{}, nil
+	}
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	v, err := time.Parse(layout, c.Query(key))
+	if err != nil {
+		return time.Time{}, invalidParamError("query", key)
+	}
+
+	return v, nil
+}
+
+// QueryUUID returns the query parameter by name as UUID.
+// Default value is optional, it is returned if the parameter is missing.
+// It returns an error with status 400 if the parameter is malformed.
+func (c *Context) QueryUUID(key string, defaultValue ...uuid.UUID) (uuid.UUID, error) {
+	if !c.QueryArgs().Has(key) {
+		if len(defaultValue) != 0 {
+			return defaultValue[0], nil
+		}
+
+		return uuid.Nil, nil
+	}
+
+	v, err := uuid.Parse(c.Query(key))
+	if err != nil {
+		return uuid.Nil, invalidParamError("query", key)
+	}
+
+	return v, nil
+}
+
+func (c *Context) requiredParam(name string) (string, error) {
+	param := c.Param(name)
+	if param == "" {
+		return "", NewBasicError(StatusBadRequest, "missing path parameter \""+name+"\"")
+	}
+
+	return param, nil
+}
+
+func invalidParamError(in, name string) error {
+	return NewBasicError(StatusBadRequest, "invalid "+in+" parameter \""+name+"\"")
+}
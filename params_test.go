@@ -0,0 +1,69 @@
+package emir
+
+import (
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func Test_TypedParams(t *testing.T) {
+	e := New(Config{})
+
+	e.GET("/users/{id}/{uuid}", func(c *Context) error {
+		id, err := c.ParamInt("id")
+		if err != nil || id != 42 {
+			t.Errorf("unexpected id: %d, error: %v", id, err)
+		}
+
+		if _, err := c.ParamUUID("uuid"); err != nil {
+			t.Error(err)
+		}
+
+		if _, err := c.ParamInt("missing"); err == nil {
+			t.Error("expected missing parameter error")
+		}
+
+		limit, err := c.QueryInt("limit", 10)
+		if err != nil || limit != 10 {
+			t.Errorf("unexpected limit: %d, error: %v", limit, err)
+		}
+
+		page, err := c.QueryInt("page")
+		if err != nil || page != 3 {
+			t.Errorf("unexpected page: %d, error: %v", page, err)
+		}
+
+		verbose, err := c.QueryBool("verbose")
+		if err != nil || !verbose {
+			t.Errorf("unexpected verbose: %v, error: %v", verbose, err)
+		}
+
+		timeout, err := c.QueryDuration("timeout")
+		if err != nil || timeout != 5*time.Second {
+			t.Errorf("unexpected timeout: %v, error: %v", timeout, err)
+		}
+
+		since, err := c.QueryTime("since", "")
+		if err != nil || since.Year() != 2021 {
+			t.Errorf("unexpected since: %v, error: %v", since, err)
+		}
+
+		if tags := c.QueryAll("tag"); len(tags) != 2 || tags[1] != "b" {
+			t.Errorf("unexpected tags: %v", tags)
+		}
+
+		_, err = c.QueryInt("bad")
+		return err
+	})
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.Request.SetRequestURI("/users/42/6ba7b810-9dad-11d1-80b4-00c04fd430c8?page=3&verbose&timeout=5s&since=2021-03-05T10:00:00Z&tag=a&tag=b&bad=x")
+
+	e.Handler()(ctx)
+
+	if code := ctx.Response.StatusCode(); code != StatusBadRequest {
+		t.Errorf("unexpected status code. expected: %d, got: %d", StatusBadRequest, code)
+	}
+}
@@ -0,0 +1,138 @@
+package emir
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pathParam represents a parameter segment of a route path.
+// Supported forms are the ones fasthttp/router understands:
+// {name}, {name?}, {name:regex} and {name:*}
+type pathParam struct {
+	Name     string
+	Pattern  string
+	Optional bool
+	CatchAll bool
+
+	// start and end are the byte offsets of the parameter in the path, braces included.
+	start int
+	end   int
+}
+
+// parsePathParams returns the parameter segments of the given path by order.
+func parsePathParams(path string) []pathParam {
+	var params []pathParam
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			continue
+		}
+
+		end := strings.IndexByte(path[i:], '}')
+		if end == -1 {
+			break
+		}
+		end += i
+
+		param := pathParam{Name: path[i+1 : end], start: i, end: end + 1}
+		if idx := strings.IndexByte(param.Name, ':'); idx != -1 {
+			param.Pattern = param.Name[idx+1:]
+			param.Name = param.Name[:idx]
+		}
+
+		switch {
+		case param.Pattern == "*":
+			param.Pattern = ""
+			param.CatchAll = true
+		case strings.HasSuffix(param.Name, "?"):
+			param.Name = strings.TrimSuffix(param.Name, "?")
+			param.Optional = true
+		}
+
+		params = append(params, param)
+		i = end
+	}
+
+	return params
+}
+
+// buildPath fills the parameters of the given path with given values by order.
+// Values that are left after filling the path parameters are encoded as query string
+// and they must be given as key-value pairs.
+func buildPath(path string, values ...interface{}) (string, error) {
+	params := parsePathParams(path)
+
+	b := make([]byte, 0, len(path))
+	last := 0
+	for i, param := range params {
+		b = append(b, path[last:param.start]...)
+		last = param.end
+
+		if i >= len(values) {
+			if param.Optional {
+				// drops the slash in front of the optional parameter as fasthttp/router does
+				if n := len(b); n > 1 && b[n-1] == '/' {
+					b = b[:n-1]
+				}
+				continue
+			}
+
+			return "", fmt.Errorf("emir: missing value for path parameter %q of %q", param.Name, path)
+		}
+
+		value := toString(values[i])
+		if !param.CatchAll {
+			b = append(b, url.PathEscape(value)...)
+			continue
+		}
+
+		segments := strings.Split(value, "/")
+		for j := range segments {
+			segments[j] = url.PathEscape(segments[j])
+		}
+		b = append(b, strings.Join(segments, "/")...)
+	}
+	b = append(b, path[last:]...)
+
+	result := string(b)
+	if len(values) <= len(params) {
+		return result, nil
+	}
+
+	query := values[len(params):]
+	if len(query)%2 != 0 {
+		return "", fmt.Errorf("emir: query values of %q must be given as key-value pairs", path)
+	}
+
+	args := make(url.Values, len(query)/2)
+	for i := 0; i < len(query); i += 2 {
+		key := toString(query[i])
+		args[key] = append(args[key], toString(query[i+1]))
+	}
+
+	return result + "?" + args.Encode(), nil
+}
+
+// toString formats given value to be used in a URL.
+func toString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	case int:
+		return strconv.Itoa(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case uint64:
+		return strconv.FormatUint(value, 10)
+	case bool:
+		return strconv.FormatBool(value)
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,149 @@
+package emir
+
+import (
+	"errors"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ProblemError represents an RFC 7807 problem details object.
+// Extensions are encoded as the members of the problem object.
+type ProblemError struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+// NewProblemError returns a problem with given status and detail.
+// The problem type is "about:blank" and the title is the status text.
+func NewProblemError(status int, detail string) *ProblemError {
+	return &ProblemError{
+		Type:   "about:blank",
+		Title:  fasthttp.StatusMessage(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *ProblemError) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+
+	return p.Title
+}
+
+// With sets an extension member of the problem
+func (p *ProblemError) With(key string, value interface{}) *ProblemError {
+	if p.Extensions == nil {
+		p.Extensions = map[string]interface{}{}
+	}
+
+	p.Extensions[key] = value
+
+	return p
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Standard members can't be overwritten by the extensions.
+func (p *ProblemError) MarshalJSON() ([]byte, error) {
+	return defaultJSONCodec.Marshal(p.members())
+}
+
+// members returns the members of the problem object
+func (p *ProblemError) members() map[string]interface{} {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	problemType := p.Type
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+	members["type"] = problemType
+
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return members
+}
+
+// ProblemErrorHandler is an error handler which renders errors as RFC 7807 "application/problem+json" responses.
+// BasicError, ValidationErrors and the mapped errors are converted to problems,
+// the other errors are rendered as 500 without detail.
+// The instance of the problem is the request URI if it isn't set.
+func ProblemErrorHandler(ctx *Context, err error) {
+	var (
+		problem          *ProblemError
+		validationErrors ValidationErrors
+	)
+
+	switch {
+	case errors.As(err, &problem):
+		p := *problem
+		problem = &p
+	case errors.As(err, &validationErrors):
+		problem = NewProblemError(StatusUnprocessableEntity, "validation failed").With("errors", validationErrors)
+	default:
+		basicError, ok := resolveError(ctx, err)
+		if !ok {
+			problem = NewProblemError(StatusInternalServerError, "")
+			break
+		}
+
+		problem = NewProblemError(basicError.StatusCode, basicError.ErrorMessage)
+		if basicError.ErrorCode != nil {
+			problem.With("code", basicError.ErrorCode)
+		}
+
+		if basicError.Details != nil {
+			problem.With("details", basicError.Details)
+		}
+
+		ReleaseBasicError(basicError)
+	}
+
+	renderProblem(ctx, problem)
+}
+
+func renderProblem(ctx *Context, problem *ProblemError) {
+	if problem.Status == 0 {
+		problem.Status = StatusInternalServerError
+	}
+
+	if problem.Title == "" {
+		problem.Title = fasthttp.StatusMessage(problem.Status)
+	}
+
+	if problem.Instance == "" {
+		problem.Instance = string(ctx.RequestURI())
+	}
+
+	body, err := ctx.jsonCodec().Marshal(problem.members())
+	if err != nil {
+		ctx.SetStatusCode(StatusInternalServerError)
+
+		return
+	}
+
+	ctx.SetStatusCode(problem.Status)
+	ctx.SetBody(body)
+	ctx.SetContentType(ContentTypeApplicationProblemJSON)
+}
@@ -0,0 +1,74 @@
+package emir
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func Test_ProblemErrorHandler(t *testing.T) {
+	e := New(Config{ErrorHandler: ProblemErrorHandler})
+
+	e.GET("/problem", func(c *Context) error {
+		return NewProblemError(StatusConflict, "user already exists").With("user", "emir").With("status", 200)
+	})
+
+	e.GET("/basic", func(c *Context) error {
+		return NewBasicError(StatusNotFound, "user not found", 1001)
+	})
+
+	e.GET("/error", func(c *Context) error {
+		return errors.New("secret database error")
+	})
+
+	handler := e.Handler()
+
+	tests := []struct {
+		uri      string
+		status   int
+		expected map[string]interface{}
+	}{
+		{"/problem", StatusConflict, map[string]interface{}{
+			"type": "about:blank", "title": "Conflict", "status": 409.0, "detail": "user already exists", "instance": "/problem", "user": "emir",
+		}},
+		{"/basic", StatusNotFound, map[string]interface{}{
+			"type": "about:blank", "title": "Not Found", "status": 404.0, "detail": "user not found", "instance": "/basic", "code": 1001.0,
+		}},
+		{"/error", StatusInternalServerError, map[string]interface{}{
+			"type": "about:blank", "title": "Internal Server Error", "status": 500.0, "instance": "/error",
+		}},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI(test.uri)
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code. expected: %d, got: %d", test.status, code)
+		}
+
+		if contentType := string(ctx.Response.Header.ContentType()); contentType != ContentTypeApplicationProblemJSON {
+			t.Errorf("unexpected content type: %s", contentType)
+		}
+
+		body := map[string]interface{}{}
+		if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(body) != len(test.expected) {
+			t.Errorf("unexpected body: %s", ctx.Response.Body())
+		}
+
+		for key, value := range test.expected {
+			if body[key] != value {
+				t.Errorf("unexpected %s member. expected: %v, got: %v", key, value, body[key])
+			}
+		}
+	}
+}
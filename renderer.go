@@ -0,0 +1,234 @@
+package emir
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v4"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// JSONRenderer renders values as "application/json"
+	JSONRenderer struct {
+		// Codec encodes the values, the default codec is used if it is nil
+		Codec JSONCodec
+	}
+
+	// XMLRenderer renders values as "application/xml"
+	XMLRenderer struct {
+	}
+
+	// MsgPackRenderer renders values as "application/msgpack"
+	MsgPackRenderer struct {
+	}
+
+	// YAMLRenderer renders values as "application/yaml"
+	YAMLRenderer struct {
+	}
+)
+
+// acceptRange is a media range of the Accept header
+type acceptRange struct {
+	mediaType string
+	subType   string
+	quality   float64
+}
+
+// ContentType implements the Renderer#ContentType function.
+func (*JSONRenderer) ContentType() string {
+	return ContentTypeApplicationJSON
+}
+
+// Render implements the Renderer#Render function.
+func (r *JSONRenderer) Render(v interface{}) ([]byte, error) {
+	if r.Codec == nil {
+		return defaultJSONCodec.Marshal(v)
+	}
+
+	return r.Codec.Marshal(v)
+}
+
+// ContentType implements the Renderer#ContentType function.
+func (*XMLRenderer) ContentType() string {
+	return ContentTypeApplicationXML
+}
+
+// Render implements the Renderer#Render function.
+func (*XMLRenderer) Render(v interface{}) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+// ContentType implements the Renderer#ContentType function.
+func (*MsgPackRenderer) ContentType() string {
+	return ContentTypeApplicationMsgPack
+}
+
+// Render implements the Renderer#Render function.
+func (*MsgPackRenderer) Render(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// ContentType implements the Renderer#ContentType function.
+func (*YAMLRenderer) ContentType() string {
+	return ContentTypeApplicationYAML
+}
+
+// Render implements the Renderer#Render function.
+func (*YAMLRenderer) Render(v interface{}) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+// defaultRenderers returns the renderers that are registered to Emir by default.
+// JSON is the first one so it is used when the request has no Accept header.
+func defaultRenderers(codec JSONCodec) []Renderer {
+	return []Renderer{&JSONRenderer{Codec: codec}, &XMLRenderer{}, &MsgPackRenderer{}, &YAMLRenderer{}}
+}
+
+// registerRenderers adds given renderers to the list.
+// A renderer replaces the registered one that has the same content type.
+func registerRenderers(renderers []Renderer, newRenderers ...Renderer) []Renderer {
+	for _, renderer := range newRenderers {
+		replaced := false
+		for i := range renderers {
+			if mediaType(renderers[i].ContentType()) == mediaType(renderer.ContentType()) {
+				renderers[i] = renderer
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			renderers = append(renderers, renderer)
+		}
+	}
+
+	return renderers
+}
+
+// negotiateRenderer returns the renderer which is the most acceptable by given Accept header.
+// The first renderer is returned if the header is empty, nil is returned if no renderer is acceptable.
+//
+// Higher quality wins, ties are broken by the specificity of the media range,
+// then by the order of the media ranges and the order of the renderers.
+func negotiateRenderer(accept string, renderers []Renderer) Renderer {
+	if len(renderers) == 0 {
+		return nil
+	}
+
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return renderers[0]
+	}
+
+	var (
+		best            Renderer
+		bestQuality     float64
+		bestSpecificity int
+		bestRange       int
+	)
+
+	for _, renderer := range renderers {
+		mediaType, subType := splitMediaType(renderer.ContentType())
+
+		matched, specificity := -1, -1
+		for i, r := range ranges {
+			s := r.match(mediaType, subType)
+			if s > specificity {
+				matched, specificity = i, s
+			}
+		}
+
+		if matched == -1 || ranges[matched].quality == 0 {
+			continue
+		}
+
+		quality := ranges[matched].quality
+		if best != nil {
+			if quality < bestQuality {
+				continue
+			}
+
+			if quality == bestQuality {
+				if specificity < bestSpecificity {
+					continue
+				}
+
+				if specificity == bestSpecificity && matched >= bestRange {
+					continue
+				}
+			}
+		}
+
+		best, bestQuality, bestSpecificity, bestRange = renderer, quality, specificity, matched
+	}
+
+	return best
+}
+
+// parseAccept parses the media ranges of given Accept header.
+// Malformed ranges are ignored.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+
+		mediaType, subType := splitMediaType(params[0])
+		if mediaType == "" || subType == "" || (mediaType == "*" && subType != "*") {
+			continue
+		}
+
+		r := acceptRange{mediaType: mediaType, subType: subType, quality: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			quality, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || quality < 0 || quality > 1 {
+				quality = 0
+			}
+			r.quality = quality
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+// match returns the specificity of the range for given media type, -1 if the range doesn't match.
+func (r acceptRange) match(mediaType, subType string) int {
+	switch {
+	case r.mediaType == mediaType && r.subType == subType:
+		return 2
+	case r.mediaType == mediaType && r.subType == "*":
+		return 1
+	case r.mediaType == "*":
+		return 0
+	}
+
+	return -1
+}
+
+// mediaType returns given content type without parameters in lower case
+func mediaType(contentType string) string {
+	if idx := strings.IndexByte(contentType, ';'); idx != -1 {
+		contentType = contentType[:idx]
+	}
+
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func splitMediaType(contentType string) (string, string) {
+	contentType = mediaType(contentType)
+
+	idx := strings.IndexByte(contentType, '/')
+	if idx == -1 {
+		return "", ""
+	}
+
+	return contentType[:idx], contentType[idx+1:]
+}
@@ -0,0 +1,107 @@
+package emir
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type csvRenderer struct {
+}
+
+func (*csvRenderer) ContentType() string {
+	return "text/csv"
+}
+
+func (*csvRenderer) Render(v interface{}) ([]byte, error) {
+	user := v.(renderedUser)
+
+	return []byte("name,age\n" + user.Name + "," + toString(user.Age) + "\n"), nil
+}
+
+type renderedUser struct {
+	Name string `json:"name" xml:"name" yaml:"name" msgpack:"name"`
+	Age  int    `json:"age" xml:"age" yaml:"age" msgpack:"age"`
+}
+
+func Test_Render(t *testing.T) {
+	e := New(Config{})
+
+	e.GET("/user", func(c *Context) error {
+		return c.Render(renderedUser{Name: "emir", Age: 20}, StatusCreated)
+	})
+
+	e.GET("/report", func(c *Context) error {
+		return c.Render(renderedUser{Name: "emir", Age: 20})
+	}).Render(&csvRenderer{})
+
+	handler := e.Handler()
+
+	tests := []struct {
+		uri         string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/user", "", StatusCreated, ContentTypeApplicationJSON, `{"name":"emir","age":20}`},
+		{"/user", "text/html, application/xml;q=0.9, */*;q=0.8", StatusCreated, ContentTypeApplicationXML, `<renderedUser><name>emir</name><age>20</age></renderedUser>`},
+		{"/user", "application/xml;q=0.5, application/json", StatusCreated, ContentTypeApplicationJSON, `{"name":"emir","age":20}`},
+		{"/user", "application/yaml", StatusCreated, ContentTypeApplicationYAML, "name: emir\nage: 20\n"},
+		{"/user", "application/msgpack", StatusCreated, ContentTypeApplicationMsgPack, "\x82\xa4name\xa4emir\xa3age\xd3\x00\x00\x00\x00\x00\x00\x00\x14"},
+		{"/user", "application/json;q=0, */*", StatusCreated, ContentTypeApplicationXML, `<renderedUser><name>emir</name><age>20</age></renderedUser>`},
+		{"/user", "text/csv", StatusNotAcceptable, "", ""},
+		{"/report", "", StatusOK, "text/csv", "name,age\nemir,20\n"},
+		{"/report", "application/*", StatusOK, ContentTypeApplicationJSON, `{"name":"emir","age":20}`},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI(test.uri)
+		if test.accept != "" {
+			ctx.Request.Header.Set(HeaderAccept, test.accept)
+		}
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %q. expected: %d, got: %d", test.accept, test.status, code)
+		}
+
+		if test.contentType == "" {
+			continue
+		}
+
+		if contentType := string(ctx.Response.Header.ContentType()); contentType != test.contentType {
+			t.Errorf("unexpected content type for %q. expected: %s, got: %s", test.accept, test.contentType, contentType)
+		}
+
+		if body := string(ctx.Response.Body()); body != test.body {
+			t.Errorf("unexpected body for %q. expected: %q, got: %q", test.accept, test.body, body)
+		}
+
+		if vary := string(ctx.Response.Header.Peek(HeaderVary)); vary != HeaderAccept {
+			t.Errorf("unexpected vary header: %s", vary)
+		}
+	}
+}
+
+func Test_ParseAccept(t *testing.T) {
+	ranges := parseAccept("text/html;level=1, application/*;q=0.5, invalid, */*;q=0.1")
+	expected := []acceptRange{
+		{mediaType: "text", subType: "html", quality: 1},
+		{mediaType: "application", subType: "*", quality: 0.5},
+		{mediaType: "*", subType: "*", quality: 0.1},
+	}
+
+	if len(ranges) != len(expected) {
+		t.Fatalf("unexpected ranges: %v", ranges)
+	}
+
+	for i := range expected {
+		if ranges[i] != expected[i] {
+			t.Errorf("unexpected range. expected: %v, got: %v", expected[i], ranges[i])
+		}
+	}
+}
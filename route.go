@@ -1,5 +1,7 @@
 package emir
 
+import "time"
+
 // Use registers given handlers as middleware to the route
 // Given handlers will be executed by given order
 func (r *Route) Use(handlers ...RequestHandler) *Route {
@@ -39,9 +41,62 @@ func (r *Route) Bind(b Binder) {
 	r.Binder = b
 }
 
+// Render registers given renderers to the route
+// Renderers of the route are preferred over the renderers of Emir by Context.Render
+func (r *Route) Render(renderers ...Renderer) *Route {
+	r.Renderers = registerRenderers(r.Renderers, renderers...)
+
+	return r
+}
+
+// UploadLimits sets the maximum count of files and the maximum size of a file in multipart forms.
+// Zero means unlimited.
+func (r *Route) UploadLimits(maxFiles int, maxFileSize int64) *Route {
+	r.MaxUploadFiles = maxFiles
+	r.MaxUploadFileSize = maxFileSize
+
+	return r
+}
+
+// Deadline sets the deadline of Context.StdContext relative to the start of the request
+func (r *Route) Deadline(d time.Duration) *Route {
+	r.RouteDeadline = d
+
+	return r
+}
+
+// Timeout sets the maximum duration of the handler chain.
+// The chain is executed in another goroutine, when it exceeds the timeout the error handler of the route renders
+// a BasicError with Config.TimeoutStatusCode that wraps context.DeadlineExceeded and Context.StdContext is canceled.
+// The chain keeps running until it returns, so it should stop when the context is canceled.
+// The error is rendered with the request and response headers and the user values that are set by the handlers
+// which have returned before the timeout, changes to the response after the timeout are ignored.
+func (r *Route) Timeout(d time.Duration) *Route {
+	r.RouteTimeout = d
+
+	return r
+}
+
 // Name sets route name
 func (r *Route) Name(name string) *Route {
 	r.RouteName = name
 
 	return r
 }
+
+// URL generates the URL of the route.
+// Given params fill the path parameters by order,
+// the remaining params are added to query string as key-value pairs.
+func (r *Route) URL(params ...interface{}) (string, error) {
+	return buildPath(r.fullPath, params...)
+}
+
+func (r *Route) info(routerMiddlewares int) RouteInfo {
+	return RouteInfo{
+		Name:        r.RouteName,
+		Method:      r.Method,
+		Path:        r.fullPath,
+		Host:        r.host,
+		Middlewares: routerMiddlewares + len(r.Middlewares) + len(r.AfterMiddlewares),
+	}
+}
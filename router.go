@@ -1,6 +1,9 @@
 package emir
 
 import (
+	"strings"
+	"time"
+
 	fastrouter "github.com/fasthttp/router"
 	"github.com/valyala/fasthttp"
 )
@@ -8,30 +11,43 @@ import (
 type router struct {
 	emir             *Emir
 	Group            *fastrouter.Group
+	prefix           string
+	host             string
 	subRouters       []Router
 	routes           []*Route
+	fallbacks        []*spaFallback
 	middlewares      []RequestHandler
 	afterMiddlewares []RequestHandler
 	errorHandler     ErrorHandler
+	errorMappings    *errorRegistry
+	timeout          time.Duration
 	Binder           Binder
 	Validator        Validator
 }
 
 func (r *router) Handle(path string, method string, handlers ...RequestHandler) *Route {
-	route := &Route{
-		RouteName:    path,
-		Path:         path,
-		Method:       method,
-		Handlers:     handlers,
-		ErrorHandler: r.errorHandler,
-		Validator:    r.Validator,
-		Binder:       r.Binder,
-	}
+	route := r.newRoute(path, method, handlers)
 	r.routes = append(r.routes, route)
 
 	return route
 }
 
+func (r *router) newRoute(path string, method string, handlers []RequestHandler) *Route {
+	return &Route{
+		RouteName:     path,
+		Path:          path,
+		Method:        method,
+		Handlers:      handlers,
+		ErrorHandler:  r.errorHandler,
+		Validator:     r.Validator,
+		Binder:        r.Binder,
+		fullPath:      r.prefix + path,
+		host:          r.host,
+		errorMappings: r.errorMappings,
+		RouteTimeout:  r.timeout,
+	}
+}
+
 func (r *router) Validate(v Validator) {
 	r.Validator = v
 }
@@ -60,10 +76,22 @@ func (r *router) After(handlers ...RequestHandler) Router {
 	return r
 }
 
+func (r *router) Timeout(d time.Duration) {
+	r.timeout = d
+}
+
 func (r *router) HandleError(handler ErrorHandler) {
 	r.errorHandler = handler
 }
 
+func (r *router) MapError(target error, status int, message ...string) {
+	r.errorMappings.mapError(target, status, message)
+}
+
+func (r *router) MapErrorType(target interface{}, status int, message ...string) {
+	r.errorMappings.mapErrorType(target, status, message)
+}
+
 func (r *router) GET(path string, handlers ...RequestHandler) *Route {
 	return r.Handle(path, MethodGet, handlers...)
 }
@@ -92,37 +120,54 @@ func (r *router) TRACE(path string, handlers ...RequestHandler) *Route {
 	return r.Handle(path, MethodTrace, handlers...)
 }
 
+func (r *router) OPTIONS(path string, handlers ...RequestHandler) *Route {
+	return r.Handle(path, MethodOptions, handlers...)
+}
+
+func (r *router) CONNECT(path string, handlers ...RequestHandler) *Route {
+	return r.Handle(path, MethodConnect, handlers...)
+}
+
+func (r *router) Any(path string, handlers ...RequestHandler) *Route {
+	return r.Handle(path, fastrouter.MethodWild, handlers...)
+}
+
+func (r *router) Match(methods []string, path string, handlers ...RequestHandler) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, r.Handle(path, method, handlers...))
+	}
+
+	return routes
+}
+
+func (r *router) WS(path string, handler func(*WSConn) error, middlewares ...RequestHandler) *Route {
+	return r.Handle(path, MethodGet, newWSHandler(handler)).Use(middlewares...)
+}
+
+func (r *router) Static(prefix, root string, cfg StaticConfig) *Route {
+	return r.Handle(strings.TrimSuffix(prefix, "/")+"/{filepath:*}", fastrouter.MethodWild, newStaticHandler(root, cfg))
+}
+
+func (r *router) SPA(prefix, index string, cfg StaticConfig) *Route {
+	route := r.newRoute(prefix, MethodGet, []RequestHandler{newSPAHandler(index, cfg)})
+	route.fallback = true
+	r.fallbacks = append(r.fallbacks, &spaFallback{prefix: r.prefix + prefix, route: route})
+
+	return route
+}
+
+func (r *router) Mount(prefix string, handler interface{}) *Route {
+	return r.Handle(strings.TrimSuffix(prefix, "/")+"/{mountpath:*}", fastrouter.MethodWild, newMountHandler(r.prefix+prefix, handler))
+}
+
 func (r *router) Handler() fasthttp.RequestHandler {
 	for _, route := range r.routes {
-		r.Group.Handle(route.Method, route.Path, func(fctx *fasthttp.RequestCtx) {
-			ctx := acquireCtx(fctx)
-			defer func() {
-				for _, deferFunc := range ctx.deferFuncs {
-					deferFunc()
-				}
-				releaseCtx(ctx)
-			}()
-
-			ctx.route = route
-			ctx.emir = r.emir
-
-			chain := append(r.middlewares, route.Middlewares...)
-			chain = append(chain, route.Handlers...)
-			chain = append(chain, route.AfterMiddlewares...)
-			chain = append(chain, r.afterMiddlewares...)
-
-			for _, handler := range chain {
-				ctx.next = false
-				if err := handler(ctx); err != nil {
-					route.ErrorHandler(ctx, err)
-					return
-				}
-
-				if !ctx.next {
-					return
-				}
-			}
-		})
+		r.Group.Handle(route.Method, route.Path, r.handle(route))
+	}
+
+	for _, fallback := range r.fallbacks {
+		fallback.handler = r.handle(fallback.route)
 	}
 
 	for _, subrouter := range r.subRouters {
@@ -132,15 +177,68 @@ func (r *router) Handler() fasthttp.RequestHandler {
 	return nil
 }
 
+// handle compiles the chain of the route with the middlewares of the router and returns its request handler
+func (r *router) handle(route *Route) fasthttp.RequestHandler {
+	route.compile(r.middlewares, r.afterMiddlewares)
+
+	return dispatch(r.emir, route)
+}
+
 func (r *router) NewGroup(path string) Router {
 	newRouter := &router{
+		emir:             r.emir,
+		host:             r.host,
 		Group:            r.Group.Group(path),
-		middlewares:      r.middlewares,
-		afterMiddlewares: r.afterMiddlewares,
+		prefix:           r.prefix + path,
+		middlewares:      append([]RequestHandler(nil), r.middlewares...),
+		afterMiddlewares: append([]RequestHandler(nil), r.afterMiddlewares...),
 		errorHandler:     r.errorHandler,
+		errorMappings:    newErrorRegistry(r.errorMappings),
+		timeout:          r.timeout,
+		Binder:           r.Binder,
+		Validator:        r.Validator,
 	}
 
 	r.subRouters = append(r.subRouters, newRouter)
 
 	return newRouter
 }
+
+func (r *router) registeredRoutes() []*Route {
+	routes := append([]*Route(nil), r.routes...)
+	for _, fallback := range r.fallbacks {
+		routes = append(routes, fallback.route)
+	}
+
+	for _, subrouter := range r.subRouters {
+		routes = append(routes, subrouter.(*router).registeredRoutes()...)
+	}
+
+	return routes
+}
+
+func (r *router) registeredFallbacks() []*spaFallback {
+	fallbacks := append([]*spaFallback(nil), r.fallbacks...)
+	for _, subrouter := range r.subRouters {
+		fallbacks = append(fallbacks, subrouter.(*router).registeredFallbacks()...)
+	}
+
+	return fallbacks
+}
+
+func (r *router) routeInfos() []RouteInfo {
+	infos := make([]RouteInfo, 0, len(r.routes)+len(r.fallbacks))
+	for _, route := range r.routes {
+		infos = append(infos, route.info(len(r.middlewares)+len(r.afterMiddlewares)))
+	}
+
+	for _, fallback := range r.fallbacks {
+		infos = append(infos, fallback.route.info(len(r.middlewares)+len(r.afterMiddlewares)))
+	}
+
+	for _, subrouter := range r.subRouters {
+		infos = append(infos, subrouter.(*router).routeInfos()...)
+	}
+
+	return infos
+}
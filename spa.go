@@ -0,0 +1,92 @@
+package emir
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// spaFallback serves the index file of a single-page app for the unmatched requests under the prefix
+type spaFallback struct {
+	prefix  string
+	route   *Route
+	handler fasthttp.RequestHandler
+}
+
+// newSPAHandler returns a request handler that serves the index file.
+// Index is a path in StaticConfig.FS if it is set, otherwise it is a path in the OS file system.
+func newSPAHandler(index string, cfg StaticConfig) RequestHandler {
+	fsys, name := cfg.FS, strings.TrimPrefix(path.Clean("/"+index), "/")
+	if fsys == nil {
+		fsys, name = os.DirFS(filepath.Dir(index)), filepath.Base(index)
+	}
+
+	return func(c *Context) error {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return fileError(err)
+		}
+
+		c.Response.Header.Add(HeaderVary, HeaderAccept)
+
+		return serveFile(c, fsys, name, info, cfg)
+	}
+}
+
+// spaNotFound returns a not found handler that executes the fallback with the longest matching prefix
+// for the GET and HEAD requests that accept "text/html". The other requests are handled by given not found handler.
+func spaNotFound(fallbacks []*spaFallback, notFound fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if len(fallbacks) == 0 {
+		return notFound
+	}
+
+	sort.SliceStable(fallbacks, func(i, j int) bool {
+		return len(fallbacks[i].prefix) > len(fallbacks[j].prefix)
+	})
+
+	return func(ctx *fasthttp.RequestCtx) {
+		if (ctx.IsGet() || ctx.IsHead()) && acceptsHTML(B2S(ctx.Request.Header.Peek(HeaderAccept))) {
+			requestPath := B2S(ctx.Path())
+			for _, fallback := range fallbacks {
+				if hasPathPrefix(requestPath, fallback.prefix) {
+					fallback.handler(ctx)
+					return
+				}
+			}
+		}
+
+		if notFound != nil {
+			notFound(ctx)
+			return
+		}
+
+		ctx.Error(fasthttp.StatusMessage(StatusNotFound), StatusNotFound)
+	}
+}
+
+// acceptsHTML reports whether the Accept header explicitly accepts "text/html".
+// "*/*" isn't enough since the API clients send it by default.
+func acceptsHTML(accept string) bool {
+	for _, r := range parseAccept(accept) {
+		if r.match("text", "html") > 0 && r.quality > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasPathPrefix reports whether the path is the prefix or it is under the prefix
+func hasPathPrefix(requestPath, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+
+	return requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/")
+}
@@ -0,0 +1,119 @@
+package emir
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/valyala/fasthttp"
+)
+
+func Test_SPA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dist/index.html":       {Data: []byte("<html>app</html>")},
+		"dist/admin/index.html": {Data: []byte("<html>admin</html>")},
+	}
+
+	e := New(Config{})
+	e.Use(func(c *Context) error {
+		c.Response.Header.Set("X-Middleware", "true")
+		return c.Next()
+	})
+
+	e.GET("/api/users", func(c *Context) error {
+		return c.JSON([]string{"emir"})
+	})
+	e.SPA("/", "dist/index.html", StaticConfig{FS: fsys})
+
+	admin := e.NewGroup("/admin")
+	admin.SPA("/", "dist/admin/index.html", StaticConfig{FS: fsys})
+
+	vh := e.NewVirtualHost("app.example.com")
+	vh.SPA("/app", "dist/index.html", StaticConfig{FS: fsys})
+
+	handler := e.Handler()
+
+	const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+	tests := []struct {
+		host   string
+		method string
+		uri    string
+		accept string
+		status int
+		body   string
+	}{
+		{"", MethodGet, "/dashboard/settings", browserAccept, StatusOK, "<html>app</html>"},
+		{"", MethodGet, "/", browserAccept, StatusOK, "<html>app</html>"},
+		{"", MethodHead, "/dashboard", browserAccept, StatusOK, "<html>app</html>"},
+		{"", MethodGet, "/admin/users/1", browserAccept, StatusOK, "<html>admin</html>"},
+		{"", MethodGet, "/administrator", browserAccept, StatusOK, "<html>app</html>"},
+		{"", MethodGet, "/api/users", browserAccept, StatusOK, `["emir"]`},
+		{"", MethodGet, "/api/missing", ContentTypeApplicationJSON, StatusNotFound, "Not Found"},
+		{"", MethodGet, "/api/missing", "*/*", StatusNotFound, "Not Found"},
+		{"", MethodGet, "/dashboard", "text/html;q=0", StatusNotFound, "Not Found"},
+		{"", MethodPost, "/dashboard", browserAccept, StatusNotFound, "Not Found"},
+		{"app.example.com", MethodGet, "/app/settings", browserAccept, StatusOK, "<html>app</html>"},
+		{"app.example.com", MethodGet, "/app/profile", browserAccept, StatusOK, "<html>app</html>"},
+		{"app.example.com", MethodGet, "/other", browserAccept, StatusNotFound, "Not Found"},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(test.method)
+		ctx.Request.SetRequestURI(test.uri)
+		ctx.Request.Header.Set(HeaderAccept, test.accept)
+		if test.host != "" {
+			ctx.Request.Header.SetHost(test.host)
+		}
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s %s%s. expected: %d, got: %d", test.method, test.host, test.uri, test.status, code)
+		}
+
+		if body := string(ctx.Response.Body()); body != test.body {
+			t.Errorf("unexpected body for %s %s%s. expected: %q, got: %q", test.method, test.host, test.uri, test.body, body)
+		}
+
+		if test.status == StatusOK && test.host == "" && string(ctx.Response.Header.Peek("X-Middleware")) != "true" {
+			t.Errorf("middleware hasn't executed for %s", test.uri)
+		}
+	}
+}
+
+func Test_SPARoutes(t *testing.T) {
+	fsys := fstest.MapFS{"index.html": {Data: []byte("<html>app</html>")}}
+
+	e := New(Config{})
+	e.GET("/", func(c *Context) error {
+		return c.PlainString("home")
+	}).Doc(RouteDoc{Summary: "home"})
+	e.SPA("/", "index.html", StaticConfig{FS: fsys})
+	e.NewGroup("/admin").SPA("/", "index.html", StaticConfig{FS: fsys}).Name("admin")
+	e.NewVirtualHost("app.example.com").SPA("/app", "index.html", StaticConfig{FS: fsys})
+
+	paths := map[string]bool{}
+	for _, route := range e.Routes() {
+		paths[route.Host+route.Path] = true
+	}
+
+	for _, path := range []string{"/admin/", "app.example.com/app"} {
+		if !paths[path] {
+			t.Errorf("fallback %s isn't listed in the routes: %v", path, paths)
+		}
+	}
+
+	if url, err := e.URL("admin"); err != nil || url != "/admin/" {
+		t.Errorf("unexpected url of the fallback: %s, %v", url, err)
+	}
+
+	doc := e.OpenAPI(OpenAPIConfig{})
+	if doc.Paths["/admin/"]["get"] == nil {
+		t.Errorf("fallback isn't documented: %v", doc.Paths)
+	}
+
+	if op := doc.Paths["/"]["get"]; op == nil || op.Summary != "home" {
+		t.Errorf("fallback overrides the route: %+v", op)
+	}
+}
@@ -0,0 +1,320 @@
+package emir
+
+import (
+	"bufio"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// sseHubBufferSize is the count of the events that are buffered for a subscriber of SSEHub
+const sseHubBufferSize = 64
+
+// sseHubHistorySize is the count of the events that SSEHub keeps per topic to resume the streams
+const sseHubHistorySize = 128
+
+var errSSEStreamClosed = errors.New("emir: server-sent event stream is closed")
+
+var sseFieldReplacer = strings.NewReplacer("\r", "", "\n", "")
+
+type (
+	// SSEEvent represents a server-sent event
+	SSEEvent struct {
+		// ID is the id of the event, clients send it as the Last-Event-ID header while reconnecting
+		ID string
+		// Event is the type of the event, clients handle the events without type as "message"
+		Event string
+		// Data is the payload of the event. Strings and byte slices are sent as is, other values are encoded as JSON
+		Data interface{}
+		// Retry is the reconnection time of the client, it is omitted if it is zero
+		Retry time.Duration
+	}
+
+	// SSEStream is a server-sent event stream opened by Context.SSE
+	// It is safe for concurrent use.
+	SSEStream struct {
+		mu          sync.Mutex
+		w           *bufio.Writer
+		codec       JSONCodec
+		lastEventID string
+		done        chan struct{}
+		closeOnce   sync.Once
+		keepAliveWG sync.WaitGroup
+	}
+
+	// SSEHub is an in-process broker that publishes server-sent events to the subscribed streams by topic.
+	// The latest events that have ids are kept per topic to resume the streams by their Last-Event-ID.
+	SSEHub struct {
+		mu          sync.RWMutex
+		subscribers map[string]map[chan SSEEvent]struct{}
+		history     map[string][]SSEEvent
+	}
+)
+
+// SSE opens a server-sent event stream and runs given function with it.
+// The function runs after the handler returns, so it must not use the Context.
+// Keep-alive comments are sent by the interval of Config.SSEKeepAliveInterval.
+// The stream is closed when the function returns, the client disconnects or the server shuts down.
+func (c *Context) SSE(fn func(stream *SSEStream) error) error {
+	stream := &SSEStream{
+		codec:       c.jsonCodec(),
+		lastEventID: string(c.Request.Header.Peek(HeaderLastEventID)),
+		done:        make(chan struct{}),
+	}
+
+	interval := DefaultSSEKeepAliveInterval
+	logger := zap.NewNop()
+	if c.emir != nil {
+		interval = c.emir.cfg.SSEKeepAliveInterval
+		logger = c.emir.Logger
+	}
+	serverDone := c.Done()
+
+	c.SetContentType(ContentTypeTextEventStream)
+	c.Response.Header.Set(HeaderCacheControl, "no-cache")
+	c.Response.Header.Set("X-Accel-Buffering", "no")
+
+	c.SetBodyStreamWriter(func(w *bufio.Writer) {
+		stream.mu.Lock()
+		stream.w = w
+		stream.mu.Unlock()
+		defer stream.shutdown()
+
+		stream.keepAliveWG.Add(1)
+		go stream.keepAlive(interval, serverDone)
+
+		if err := fn(stream); err != nil && err != errSSEStreamClosed {
+			logger.Error("server-sent event stream failed", zap.Error(err))
+		}
+	})
+
+	return nil
+}
+
+// LastEventID returns the Last-Event-ID header of the request.
+// It is the id of the last event the client received before reconnecting.
+func (s *SSEStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Done returns a channel that is closed when the stream is closed.
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Send writes given event to the stream and flushes it.
+// It returns an error if the stream is closed or the client is disconnected.
+func (s *SSEStream) Send(event SSEEvent) error {
+	var data []byte
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = s.codec.Marshal(v); err != nil {
+			return err
+		}
+	}
+
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: " + sseFieldReplacer.Replace(event.ID) + "\n")
+	}
+
+	if event.Event != "" {
+		b.WriteString("event: " + sseFieldReplacer.Replace(event.Event) + "\n")
+	}
+
+	if event.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+// Comment writes given text as a comment to the stream.
+// Comments are ignored by the clients, they keep the connection alive.
+func (s *SSEStream) Comment(text string) error {
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(": " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+func (s *SSEStream) write(frame string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return errSSEStreamClosed
+	default:
+	}
+
+	if _, err := s.w.WriteString(frame); err != nil {
+		s.close()
+		return err
+	}
+
+	if err := s.w.Flush(); err != nil {
+		s.close()
+		return err
+	}
+
+	return nil
+}
+
+func (s *SSEStream) keepAlive(interval time.Duration, serverDone <-chan struct{}) {
+	defer s.keepAliveWG.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Comment("keep-alive"); err != nil {
+				return
+			}
+		case <-serverDone:
+			s.close()
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// shutdown closes the stream and waits for the keep-alive goroutine.
+// The lock is held while closing, so no write is in progress when the body writer returns the writer to fasthttp.
+func (s *SSEStream) shutdown() {
+	s.mu.Lock()
+	s.close()
+	s.mu.Unlock()
+
+	s.keepAliveWG.Wait()
+}
+
+func (s *SSEStream) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// NewSSEHub returns an empty SSEHub
+func NewSSEHub() *SSEHub {
+	return &SSEHub{
+		subscribers: map[string]map[chan SSEEvent]struct{}{},
+		history:     map[string][]SSEEvent{},
+	}
+}
+
+// Publish sends given event to the streams that are subscribed to given topic.
+// Events are dropped for the subscribers that can't keep up with the publisher.
+func (h *SSEHub) Publish(topic string, event SSEEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if event.ID != "" {
+		history := append(h.history[topic], event)
+		if len(history) > sseHubHistorySize {
+			history = history[len(history)-sseHubHistorySize:]
+		}
+		h.history[topic] = history
+	}
+
+	for events := range h.subscribers[topic] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// Serve subscribes given stream to given topics and sends the published events until the stream is closed.
+// If the client has a Last-Event-ID, the events published after it are sent first.
+func (h *SSEHub) Serve(stream *SSEStream, topics ...string) error {
+	events := make(chan SSEEvent, sseHubBufferSize)
+
+	h.mu.Lock()
+	var missed []SSEEvent
+	for _, topic := range topics {
+		if h.subscribers[topic] == nil {
+			h.subscribers[topic] = map[chan SSEEvent]struct{}{}
+		}
+		h.subscribers[topic][events] = struct{}{}
+
+		missed = append(missed, h.eventsAfter(topic, stream.LastEventID())...)
+	}
+	h.mu.Unlock()
+
+	defer h.unsubscribe(events, topics)
+
+	for _, event := range missed {
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-stream.Done():
+			return nil
+		}
+	}
+}
+
+// Subscribers returns the count of the streams that are subscribed to given topic
+func (h *SSEHub) Subscribers(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.subscribers[topic])
+}
+
+// eventsAfter returns the events of the topic published after the event that has given id
+func (h *SSEHub) eventsAfter(topic, id string) []SSEEvent {
+	if id == "" {
+		return nil
+	}
+
+	history := h.history[topic]
+	for i := range history {
+		if history[i].ID == id {
+			return append([]SSEEvent(nil), history[i+1:]...)
+		}
+	}
+
+	return nil
+}
+
+func (h *SSEHub) unsubscribe(events chan SSEEvent, topics []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, topic := range topics {
+		delete(h.subscribers[topic], events)
+		if len(h.subscribers[topic]) == 0 {
+			delete(h.subscribers, topic)
+		}
+	}
+}
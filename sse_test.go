@@ -0,0 +1,169 @@
+package emir
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func Test_SSE(t *testing.T) {
+	e := New(Config{})
+
+	var lastEventID string
+	e.GET("/events", func(c *Context) error {
+		return c.SSE(func(stream *SSEStream) error {
+			lastEventID = stream.LastEventID()
+
+			if err := stream.Send(SSEEvent{ID: "1", Event: "greeting", Data: "hello\nworld", Retry: 3 * time.Second}); err != nil {
+				return err
+			}
+
+			if err := stream.Comment("ping"); err != nil {
+				return err
+			}
+
+			return stream.Send(SSEEvent{Data: map[string]int{"count": 2}})
+		})
+	})
+
+	var req fasthttp.Request
+	req.Header.SetMethod(MethodGet)
+	req.SetRequestURI("/events")
+	req.Header.Set(HeaderLastEventID, "0")
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Init(&req, nil, nil)
+
+	e.Handler()(ctx)
+
+	if contentType := string(ctx.Response.Header.ContentType()); contentType != ContentTypeTextEventStream {
+		t.Errorf("unexpected content type: %s", contentType)
+	}
+
+	expected := "id: 1\nevent: greeting\nretry: 3000\ndata: hello\ndata: world\n\n: ping\n\ndata: {\"count\":2}\n\n"
+	if body := string(ctx.Response.Body()); body != expected {
+		t.Errorf("unexpected body. expected: %q, got: %q", expected, body)
+	}
+
+	if lastEventID != "0" {
+		t.Errorf("unexpected last event id: %s", lastEventID)
+	}
+}
+
+func Test_SSEClose(t *testing.T) {
+	e := New(Config{SSEKeepAliveInterval: time.Millisecond})
+
+	senderErr := make(chan error, 1)
+	e.GET("/events", func(c *Context) error {
+		return c.SSE(func(stream *SSEStream) error {
+			// the sender outlives the function, it must not write after the stream is closed
+			go func() {
+				for {
+					if err := stream.Send(SSEEvent{Data: "tick"}); err != nil {
+						senderErr <- err
+						return
+					}
+				}
+			}()
+
+			time.Sleep(5 * time.Millisecond)
+
+			return nil
+		})
+	})
+
+	var req fasthttp.Request
+	req.Header.SetMethod(MethodGet)
+	req.SetRequestURI("/events")
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Init(&req, nil, nil)
+
+	e.Handler()(ctx)
+
+	body := string(ctx.Response.Body())
+	if !strings.Contains(body, "data: tick\n\n") || !strings.Contains(body, ": keep-alive\n\n") {
+		t.Errorf("unexpected body: %q", body)
+	}
+
+	select {
+	case err := <-senderErr:
+		if err != errSSEStreamClosed {
+			t.Errorf("unexpected sender error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("sender hasn't stopped after the stream is closed")
+	}
+}
+
+func Test_SSEHub(t *testing.T) {
+	hub := NewSSEHub()
+	hub.Publish("orders", SSEEvent{ID: "1", Data: "first"})
+	hub.Publish("orders", SSEEvent{ID: "2", Data: "second"})
+
+	buf := new(syncBuffer)
+	stream := &SSEStream{
+		w:           bufio.NewWriter(buf),
+		codec:       defaultJSONCodec,
+		lastEventID: "1",
+		done:        make(chan struct{}),
+	}
+
+	served := make(chan error)
+	go func() {
+		served <- hub.Serve(stream, "orders", "users")
+	}()
+
+	for hub.Subscribers("users") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	hub.Publish("users", SSEEvent{Event: "joined", Data: "emir"})
+	hub.Publish("payments", SSEEvent{Data: "ignored"})
+
+	expected := "id: 2\ndata: second\n\nevent: joined\ndata: emir\n\n"
+	for deadline := time.Now().Add(time.Second); buf.String() != expected && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	if body := buf.String(); body != expected {
+		t.Errorf("unexpected body. expected: %q, got: %q", expected, body)
+	}
+
+	stream.close()
+	if err := <-served; err != nil {
+		t.Fatal(err)
+	}
+
+	if hub.Subscribers("orders") != 0 || hub.Subscribers("users") != 0 {
+		t.Error("stream isn't unsubscribed")
+	}
+
+	if err := stream.Send(SSEEvent{Data: "closed"}); err != errSSEStreamClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package emir
+
+import (
+	"errors"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// StaticConfig carries configuration for the static file routes
+type StaticConfig struct {
+	// FS is the file system that the root directory is in. The OS file system is used if it is nil.
+	// It can be an embed.FS.
+	FS fs.FS
+	// Index is the list of the index file names of the directories, it is ["index.html"] if it is nil
+	Index []string
+	// Browse enables listing the directories that have no index file
+	Browse bool
+	// Compressed enables serving the precompressed ".br" and ".gz" siblings of the files
+	// to the clients that accept them
+	Compressed bool
+	// MaxAge is the max-age of the Cache-Control header, the header is omitted if it is zero
+	MaxAge time.Duration
+}
+
+// precompressedEncodings are the supported precompressed file encodings by preference order
+var precompressedEncodings = []struct {
+	encoding  string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// readCloser closes the file after the limited body stream is written
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// newStaticHandler returns a request handler that serves the files of the root directory by the "filepath" path parameter.
+// Errors are returned as BasicErrors, so they are rendered by the error handler of the route.
+func newStaticHandler(root string, cfg StaticConfig) RequestHandler {
+	var fsys fs.FS
+	if cfg.FS == nil {
+		fsys = os.DirFS(root)
+	} else if root = strings.Trim(root, "/"); root == "" || root == "." {
+		fsys = cfg.FS
+	} else {
+		sub, err := fs.Sub(cfg.FS, root)
+		if err != nil {
+			panic("emir: invalid static root " + strconv.Quote(root) + ": " + err.Error())
+		}
+		fsys = sub
+	}
+
+	if cfg.Index == nil {
+		cfg.Index = []string{"index.html"}
+	}
+
+	return func(c *Context) error {
+		if !c.IsGet() && !c.IsHead() {
+			c.Response.Header.Set(HeaderAllow, MethodGet+", "+MethodHead)
+			return NewBasicError(StatusMethodNotAllowed, "Method Not Allowed")
+		}
+
+		// Clean resolves the ".." elements without leaving the root, ValidPath rejects anything left
+		name := strings.TrimPrefix(path.Clean("/"+c.Param("filepath")), "/")
+		if name == "" {
+			name = "."
+		}
+
+		if !fs.ValidPath(name) || strings.ContainsAny(name, "\\\x00") {
+			return NewBasicError(StatusNotFound, "Not Found")
+		}
+
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return fileError(err)
+		}
+
+		if !info.IsDir() {
+			return serveFile(c, fsys, name, info, cfg)
+		}
+
+		if requestPath := B2S(c.Path()); !strings.HasSuffix(requestPath, "/") {
+			uri := requestPath + "/"
+			if query := c.URI().QueryString(); len(query) != 0 {
+				uri += "?" + string(query)
+			}
+			c.Redirect(uri, StatusMovedPermanently)
+
+			return nil
+		}
+
+		for _, index := range cfg.Index {
+			indexName := path.Join(name, index)
+			if info, err := fs.Stat(fsys, indexName); err == nil && !info.IsDir() {
+				return serveFile(c, fsys, indexName, info, cfg)
+			}
+		}
+
+		if !cfg.Browse {
+			return NewBasicError(StatusForbidden, "Forbidden")
+		}
+
+		return listDirectory(c, fsys, name)
+	}
+}
+
+// serveFile sends the file with the cache, range and encoding headers
+func serveFile(c *Context, fsys fs.FS, name string, info fs.FileInfo, cfg StaticConfig) error {
+	contentType := mime.TypeByExtension(path.Ext(name))
+
+	if cfg.Compressed {
+		c.Response.Header.Add(HeaderVary, HeaderAcceptEncoding)
+
+		for _, precompressed := range precompressedEncodings {
+			if !c.Request.Header.HasAcceptEncoding(precompressed.encoding) {
+				continue
+			}
+
+			compressedInfo, err := fs.Stat(fsys, name+precompressed.extension)
+			if err != nil || compressedInfo.IsDir() {
+				continue
+			}
+
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+
+			c.Response.Header.Set(HeaderContentEncoding, precompressed.encoding)
+			name, info = name+precompressed.extension, compressedInfo
+			break
+		}
+	}
+
+	if cfg.MaxAge > 0 {
+		c.Response.Header.Set(HeaderCacheControl, "public, max-age="+strconv.FormatInt(int64(cfg.MaxAge/time.Second), 10))
+	}
+
+	modTime := info.ModTime()
+	if !modTime.IsZero() {
+		c.Response.Header.Set(HeaderLastModified, string(fasthttp.AppendHTTPDate(nil, modTime)))
+
+		if since, err := fasthttp.ParseHTTPDate(c.Request.Header.Peek(HeaderIfModifiedSince)); err == nil && !modTime.Truncate(time.Second).After(since) {
+			c.NotModified()
+
+			return nil
+		}
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fileError(err)
+	}
+
+	if contentType == "" {
+		contentType = detectContentType(f)
+	}
+
+	size := int(info.Size())
+	start, end := 0, size-1
+
+	c.Response.Header.Set(HeaderAcceptRanges, "bytes")
+	if byteRange := c.Request.Header.Peek(HeaderRange); len(byteRange) != 0 && size != 0 {
+		if start, end, err = fasthttp.ParseByteRange(byteRange, size); err != nil {
+			f.Close()
+			c.Response.Header.Set(HeaderContentRange, "bytes */"+strconv.Itoa(size))
+
+			return NewBasicError(StatusRequestedRangeNotSatisfiable, "Requested Range Not Satisfiable")
+		}
+
+		c.Response.Header.SetContentRange(start, end, size)
+		c.SetStatusCode(StatusPartialContent)
+	}
+
+	var body io.Reader = f
+	if start != 0 {
+		seeker, ok := f.(io.Seeker)
+		if !ok {
+			f.Close()
+			return NewBasicError(StatusRequestedRangeNotSatisfiable, "Requested Range Not Satisfiable")
+		}
+
+		if _, err := seeker.Seek(int64(start), io.SeekStart); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if length := end - start + 1; length != size {
+		body = io.LimitReader(f, int64(length))
+	}
+
+	c.SetContentType(contentType)
+	c.SetBodyStream(readCloser{body, f}, end-start+1)
+
+	return nil
+}
+
+// detectContentType sniffs the content type of the file, the file is rewound after sniffing.
+// The content type is "application/octet-stream" if the file can't be rewound.
+func detectContentType(f fs.File) string {
+	seeker, ok := f.(io.Seeker)
+	if !ok {
+		return "application/octet-stream"
+	}
+
+	buf := make([]byte, 512)
+	n, _ := io.ReadFull(f, buf)
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return "application/octet-stream"
+	}
+
+	return http.DetectContentType(buf[:n])
+}
+
+// listDirectory sends a HTML page that lists the entries of the directory
+func listDirectory(c *Context, fsys fs.FS, name string) error {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return fileError(err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	title := html.EscapeString(B2S(c.Path()))
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><title>" + title + "</title></head><body>\n<h1>" + title + "</h1>\n<ul>\n")
+	if name != "." {
+		b.WriteString("<li><a href=\"../\">../</a></li>\n")
+	}
+
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+
+		b.WriteString("<li><a href=\"" + html.EscapeString((&url.URL{Path: entryName}).EscapedPath()) + "\">" + html.EscapeString(entryName) + "</a></li>\n")
+	}
+	b.WriteString("</ul>\n</body></html>\n")
+
+	return c.HTML(b.String())
+}
+
+// fileError converts given file system error to a BasicError
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return NewBasicError(StatusNotFound, "Not Found")
+	case errors.Is(err, fs.ErrPermission):
+		return NewBasicError(StatusForbidden, "Forbidden")
+	}
+
+	return err
+}
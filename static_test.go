@@ -0,0 +1,143 @@
+package emir
+
+import (
+	"io/ioutil"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func Test_Static(t *testing.T) {
+	modTime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"public/hello.txt":        {Data: []byte("hello world"), ModTime: modTime},
+		"public/docs/index.html":  {Data: []byte("<h1>docs</h1>"), ModTime: modTime},
+		"public/assets/app.js":    {Data: []byte("console.log('app')"), ModTime: modTime},
+		"public/assets/app.js.gz": {Data: []byte("gzipped"), ModTime: modTime},
+	}
+
+	dir, err := ioutil.TempDir("", "emir-static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Mkdir(filepath.Join(dir, "private"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "private", "data.bin"), []byte{0x00, 0x01, 0x02}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(Config{})
+	e.Use(func(c *Context) error {
+		c.Response.Header.Set("X-Middleware", "true")
+		return c.Next()
+	})
+	e.Static("/static/", "public", StaticConfig{FS: fsys, Browse: true, Compressed: true, MaxAge: time.Hour})
+	e.Static("/files", dir, StaticConfig{})
+
+	handler := e.Handler()
+
+	tests := []struct {
+		method  string
+		uri     string
+		headers map[string]string
+		status  int
+		body    string
+		expect  map[string]string
+	}{
+		{MethodGet, "/static/hello.txt", nil, StatusOK, "hello world", map[string]string{
+			HeaderContentType:  "text/plain; charset=utf-8",
+			HeaderCacheControl: "public, max-age=3600",
+			HeaderLastModified: "Sat, 02 Jan 2021 03:04:05 GMT",
+			HeaderAcceptRanges: "bytes",
+			"X-Middleware":     "true",
+		}},
+		{MethodHead, "/static/hello.txt", nil, StatusOK, "", map[string]string{HeaderContentLength: "11"}},
+		{MethodGet, "/static/hello.txt", map[string]string{HeaderRange: "bytes=6-"}, StatusPartialContent, "world", map[string]string{
+			HeaderContentRange: "bytes 6-10/11",
+		}},
+		{MethodGet, "/static/hello.txt", map[string]string{HeaderRange: "bytes=0-4"}, StatusPartialContent, "hello", nil},
+		{MethodGet, "/static/hello.txt", map[string]string{HeaderRange: "bytes=20-30"}, StatusRequestedRangeNotSatisfiable, "", map[string]string{
+			HeaderContentRange: "bytes */11",
+		}},
+		{MethodGet, "/static/hello.txt", map[string]string{HeaderIfModifiedSince: "Sat, 02 Jan 2021 03:04:05 GMT"}, StatusNotModified, "", nil},
+		{MethodGet, "/static/docs", nil, StatusMovedPermanently, "", nil},
+		{MethodGet, "/static/docs/", nil, StatusOK, "<h1>docs</h1>", nil},
+		{MethodGet, "/static/assets/", nil, StatusOK, `<a href="app.js">app.js</a>`, nil},
+		{MethodGet, "/static/assets/app.js", map[string]string{HeaderAcceptEncoding: "gzip, deflate"}, StatusOK, "gzipped", map[string]string{
+			HeaderContentEncoding: "gzip",
+			HeaderContentType:     mime.TypeByExtension(".js"),
+			HeaderVary:            HeaderAcceptEncoding,
+		}},
+		{MethodGet, "/static/assets/app.js", nil, StatusOK, "console.log('app')", nil},
+		{MethodPost, "/static/hello.txt", nil, StatusMethodNotAllowed, "", map[string]string{HeaderAllow: "GET, HEAD"}},
+		{MethodGet, "/static/missing.txt", nil, StatusNotFound, `"message":"Not Found"`, nil},
+		{MethodGet, "/files/private/data.bin", nil, StatusOK, "\x00\x01\x02", map[string]string{HeaderContentType: "application/octet-stream"}},
+		{MethodGet, "/files/private/", nil, StatusForbidden, "", nil},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(test.method)
+		ctx.Request.SetRequestURI(test.uri)
+		for key, value := range test.headers {
+			ctx.Request.Header.Set(key, value)
+		}
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s %s. expected: %d, got: %d", test.method, test.uri, test.status, code)
+		}
+
+		if body := string(ctx.Response.Body()); !strings.Contains(body, test.body) {
+			t.Errorf("unexpected body for %s. expected: %q, got: %q", test.uri, test.body, body)
+		}
+
+		for key, value := range test.expect {
+			if header := string(ctx.Response.Header.Peek(key)); header != value {
+				t.Errorf("unexpected %s header for %s. expected: %q, got: %q", key, test.uri, value, header)
+			}
+		}
+	}
+}
+
+func Test_StaticTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emir-static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Mkdir(filepath.Join(dir, "public"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := newStaticHandler(filepath.Join(dir, "public"), StaticConfig{})
+
+	for _, name := range []string{"../secret.txt", "../../secret.txt", "..\\secret.txt", "/../secret.txt"} {
+		ctx := acquireCtx(new(fasthttp.RequestCtx))
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.SetUserValue("filepath", name)
+
+		err := handler(ctx)
+		if basicError, ok := err.(*BasicError); !ok || basicError.StatusCode != StatusNotFound {
+			t.Errorf("unexpected result for %s: %v, body: %s", name, err, ctx.Response.Body())
+		}
+
+		releaseCtx(ctx)
+	}
+}
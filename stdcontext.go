@@ -0,0 +1,86 @@
+package emir
+
+import (
+	"context"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// requestIDContextKey is the context key of the request id
+type requestIDContextKey struct{}
+
+// mountContextKey is the user value key of the std context of the request that is forwarded to a mounted app
+const mountContextKey = "emir.mountContext"
+
+// requestValuesContext resolves the string keys from the user values and the request id of the request
+type requestValuesContext struct {
+	context.Context
+	fctx *fasthttp.RequestCtx
+}
+
+func (c requestValuesContext) Value(key interface{}) interface{} {
+	switch key := key.(type) {
+	case string:
+		if value := c.fctx.UserValue(key); value != nil {
+			return value
+		}
+	case requestIDContextKey:
+		return string(c.fctx.Request.Header.Peek(HeaderXRequestID))
+	}
+
+	return c.Context.Value(key)
+}
+
+// StdContext returns a context.Context of the request for the code that doesn't depend on emir.
+// The context is canceled when the request is completed or Emir.Shutdown is called,
+// and it has the deadline of the route if Route.Deadline or Route.Timeout is set, the earlier one is used.
+// The context isn't canceled when the client disconnects, fasthttp doesn't notify the handlers about it.
+// StreamJSON and JSONLines cancel it when the stream ends instead, including when the client disconnects.
+// In a mounted *Emir, the context is derived from the context of the request in the app that mounts it,
+// so it is canceled by the shutdown of the app that serves the request.
+// String keys are resolved from the user values and the request id is available via RequestIDFromContext.
+// Like the user values, the values of the context must not be accessed after the request is completed.
+func (c *Context) StdContext() context.Context {
+	if c.stdCtx != nil {
+		return c.stdCtx
+	}
+
+	parent := context.Background()
+	if mounted, ok := c.UserValue(mountContextKey).(context.Context); ok {
+		parent = mounted
+	} else if c.emir != nil {
+		parent = c.emir.ctx
+	}
+
+	var deadline time.Duration
+	if c.route != nil {
+		deadline = c.route.RouteDeadline
+		if timeout := c.route.RouteTimeout; timeout > 0 && (deadline == 0 || timeout < deadline) {
+			deadline = timeout
+		}
+	}
+
+	var ctx context.Context = requestValuesContext{parent, c.RequestCtx}
+	if deadline > 0 {
+		start := c.Time()
+		if start.IsZero() {
+			start = time.Now()
+		}
+
+		ctx, c.stdCancel = context.WithDeadline(ctx, start.Add(deadline))
+	} else {
+		ctx, c.stdCancel = context.WithCancel(ctx)
+	}
+
+	c.stdCtx = ctx
+
+	return ctx
+}
+
+// RequestIDFromContext returns the request id of the context that is returned by Context.StdContext
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+
+	return requestID
+}
@@ -0,0 +1,90 @@
+package emir
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func Test_StdContext(t *testing.T) {
+	var stdCtx context.Context
+
+	e := New(Config{})
+	e.Use(func(c *Context) error {
+		c.SetUserValue("user", "emir")
+		return c.Next()
+	})
+
+	e.GET("/users/{id}", func(c *Context) error {
+		stdCtx = c.StdContext()
+
+		if stdCtx != c.StdContext() {
+			t.Error("context isn't reused in the same request")
+		}
+
+		if user := stdCtx.Value("user"); user != "emir" {
+			t.Errorf("unexpected user value: %v", user)
+		}
+
+		if id := stdCtx.Value("id"); id != "1" {
+			t.Errorf("unexpected path parameter: %v", id)
+		}
+
+		if requestID := RequestIDFromContext(stdCtx); requestID != "request-1" {
+			t.Errorf("unexpected request id: %s", requestID)
+		}
+
+		deadline, ok := stdCtx.Deadline()
+		if !ok || time.Until(deadline) > time.Minute || time.Until(deadline) <= 0 {
+			t.Errorf("unexpected deadline: %v", deadline)
+		}
+
+		if err := stdCtx.Err(); err != nil {
+			t.Errorf("context is done before the request is completed: %v", err)
+		}
+
+		return nil
+	}).Deadline(time.Minute)
+
+	handler := e.Handler()
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.Request.SetRequestURI("/users/1")
+	ctx.Request.Header.Set(HeaderXRequestID, "request-1")
+
+	handler(ctx)
+
+	if stdCtx == nil {
+		t.Fatal("handler hasn't executed")
+	}
+
+	if err := stdCtx.Err(); err != context.Canceled {
+		t.Errorf("context isn't canceled after the request: %v", err)
+	}
+}
+
+func Test_StdContextShutdown(t *testing.T) {
+	e := New(Config{})
+
+	ctx := acquireCtx(new(fasthttp.RequestCtx))
+	defer releaseCtx(ctx)
+	ctx.emir = e
+
+	stdCtx := ctx.StdContext()
+	if _, ok := stdCtx.Deadline(); ok {
+		t.Error("context has a deadline without the route deadline")
+	}
+
+	if err := e.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-stdCtx.Done():
+	case <-time.After(time.Second):
+		t.Error("context isn't canceled by shutdown")
+	}
+}
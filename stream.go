@@ -0,0 +1,207 @@
+package emir
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// StreamIterator returns the next element of a stream.
+// It must return io.EOF when there are no more elements.
+type StreamIterator func() (interface{}, error)
+
+var errUnsupportedStreamSource = errors.New("emir: stream source must be a StreamIterator or a channel")
+
+// streamErrorRecord is the last line of a JSON lines stream whose source failed,
+// so the clients can tell a failed stream from a complete one.
+// The message is the message of the BasicError if the error is a BasicError, otherwise it is generic.
+type streamErrorRecord struct {
+	Error string `json:"error"`
+}
+
+// StreamJSON sends the elements of given source as a JSON array with given status code.
+// Elements are encoded and flushed to the client one by one, so the whole array is never kept in memory.
+//
+// Source must be a StreamIterator, a func() (interface{}, error) or a channel which is closed when the stream ends.
+// The response is already started when the source fails, so the array is left unterminated and the error is logged.
+//
+// The source is consumed after the handler returns, it must not use the Context.
+// The context returned by Context.StdContext is canceled when the stream ends instead of when the handler returns,
+// including when the client disconnects or Emir.Shutdown is called. Producers must stop when the context is done,
+// a channel isn't drained after the stream stops, so the producer should select on the done channel while sending.
+// Status code is optional.
+func (c *Context) StreamJSON(source interface{}, statusCode ...int) error {
+	return c.stream(source, ContentTypeApplicationJSON, false, statusCode...)
+}
+
+// JSONLines sends the elements of given source as newline delimited JSON with given status code.
+// It behaves like StreamJSON, but every element is written as a separate line.
+// When the source fails, the stream ends with a {"error": "message"} line and the error is logged.
+// Status code is optional.
+func (c *Context) JSONLines(source interface{}, statusCode ...int) error {
+	return c.stream(source, ContentTypeApplicationNDJSON, true, statusCode...)
+}
+
+func (c *Context) stream(source interface{}, contentType string, lines bool, statusCode ...int) error {
+	// the stream outlives the handler, so it takes over the cancellation of the std context from the Context
+	ctx := c.StdContext()
+	next, err := streamSource(source, ctx)
+	if err != nil {
+		return err
+	}
+
+	cancel := c.stdCancel
+	c.stdCancel = nil
+
+	codec := c.jsonCodec()
+	logger := zap.NewNop()
+	if c.emir != nil {
+		logger = c.emir.Logger
+	}
+
+	c.setStatus(statusCode...)
+	c.SetContentType(contentType)
+
+	c.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		sourceErr, writeErr := writeStream(w, next, codec, lines)
+		if sourceErr != nil {
+			logger.Error("json stream failed", zap.Error(sourceErr))
+		}
+
+		if writeErr != nil {
+			logger.Debug("json stream is interrupted by the client", zap.Error(writeErr))
+		}
+	})
+
+	return nil
+}
+
+// writeStream writes the elements of the source to given writer and flushes after every element.
+// Errors of the source and the encoder are returned separately from the errors of the writer.
+// JSON lines streams are terminated with an error record when the source or the encoder fails.
+func writeStream(w *bufio.Writer, next StreamIterator, codec JSONCodec, lines bool) (sourceErr, writeErr error) {
+	sourceErr, writeErr = writeElements(w, next, codec, lines)
+	if sourceErr != nil && lines {
+		writeErr = writeErrorRecord(w, codec, sourceErr)
+	}
+
+	return sourceErr, writeErr
+}
+
+// writeErrorRecord writes the error record of a failed JSON lines stream
+func writeErrorRecord(w *bufio.Writer, codec JSONCodec, err error) error {
+	record := streamErrorRecord{Error: fasthttp.StatusMessage(StatusInternalServerError)}
+
+	var basicError *BasicError
+	if errors.As(err, &basicError) {
+		record.Error = basicError.ErrorMessage
+	}
+
+	body, err := codec.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+func writeElements(w *bufio.Writer, next StreamIterator, codec JSONCodec, lines bool) (sourceErr, writeErr error) {
+	if !lines {
+		if err := w.WriteByte('['); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := 0; ; i++ {
+		v, err := next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err, nil
+		}
+
+		body, err := codec.Marshal(v)
+		if err != nil {
+			return err, nil
+		}
+
+		if i != 0 && !lines {
+			if err := w.WriteByte(','); err != nil {
+				return nil, err
+			}
+		}
+
+		if lines {
+			body = append(body, '\n')
+		}
+
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+
+		if err := w.Flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !lines {
+		if err := w.WriteByte(']'); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// streamSource converts given source to an iterator.
+// A channel source stops with the error of given context when the context is done before the channel is closed.
+func streamSource(source interface{}, ctx context.Context) (StreamIterator, error) {
+	switch next := source.(type) {
+	case StreamIterator:
+		return next, nil
+	case func() (interface{}, error):
+		return next, nil
+	}
+
+	ch := reflect.ValueOf(source)
+	if ch.Kind() != reflect.Chan || ch.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, errUnsupportedStreamSource
+	}
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+
+	next := func() (interface{}, error) {
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 1 {
+			return nil, ctx.Err()
+		}
+
+		if !ok {
+			return nil, io.EOF
+		}
+
+		return v.Interface(), nil
+	}
+
+	return next, nil
+}
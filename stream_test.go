@@ -0,0 +1,221 @@
+package emir
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type failingWriter struct {
+}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+// disconnectingWriter fails after the limit is reached like a client that disconnects in the middle of a stream
+type disconnectingWriter struct {
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *disconnectingWriter) Write(p []byte) (int, error) {
+	if w.body.Len() >= w.limit {
+		return 0, errors.New("connection reset by peer")
+	}
+
+	return w.body.Write(p)
+}
+
+func Test_StreamJSON(t *testing.T) {
+	e := New(Config{Logger: zap.NewNop()})
+
+	e.GET("/array", func(c *Context) error {
+		i := 0
+		return c.StreamJSON(func() (interface{}, error) {
+			if i == 3 {
+				return nil, io.EOF
+			}
+			i++
+
+			return map[string]int{"id": i}, nil
+		}, StatusAccepted)
+	})
+
+	e.GET("/lines", func(c *Context) error {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for i := 1; i <= 3; i++ {
+				ch <- i
+			}
+		}()
+
+		return c.JSONLines(ch)
+	})
+
+	failingSource := func(err error) StreamIterator {
+		i := 0
+		return func() (interface{}, error) {
+			if i == 2 {
+				return nil, err
+			}
+			i++
+
+			return i, nil
+		}
+	}
+
+	e.GET("/lines/failed", func(c *Context) error {
+		return c.JSONLines(failingSource(errors.New("connection to the database is lost")))
+	})
+
+	e.GET("/lines/failed-basic", func(c *Context) error {
+		return c.JSONLines(failingSource(NewBasicError(StatusServiceUnavailable, "search is unavailable")))
+	})
+
+	e.GET("/empty", func(c *Context) error {
+		ch := make(chan int)
+		close(ch)
+
+		return c.StreamJSON(ch)
+	})
+
+	e.GET("/invalid", func(c *Context) error {
+		return c.StreamJSON([]int{1, 2, 3})
+	})
+
+	handler := e.Handler()
+
+	tests := []struct {
+		uri         string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/array", StatusAccepted, ContentTypeApplicationJSON, `[{"id":1},{"id":2},{"id":3}]`},
+		{"/lines", StatusOK, ContentTypeApplicationNDJSON, "1\n2\n3\n"},
+		{"/lines/failed", StatusOK, ContentTypeApplicationNDJSON, "1\n2\n{\"error\":\"Internal Server Error\"}\n"},
+		{"/lines/failed-basic", StatusOK, ContentTypeApplicationNDJSON, "1\n2\n{\"error\":\"search is unavailable\"}\n"},
+		{"/empty", StatusOK, ContentTypeApplicationJSON, "[]"},
+		{"/invalid", StatusInternalServerError, "", ""},
+	}
+
+	for _, test := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.SetMethod(MethodGet)
+		ctx.Request.SetRequestURI(test.uri)
+
+		handler(ctx)
+
+		if code := ctx.Response.StatusCode(); code != test.status {
+			t.Errorf("unexpected status code for %s. expected: %d, got: %d", test.uri, test.status, code)
+		}
+
+		if test.contentType == "" {
+			continue
+		}
+
+		if contentType := string(ctx.Response.Header.ContentType()); contentType != test.contentType {
+			t.Errorf("unexpected content type for %s. expected: %s, got: %s", test.uri, test.contentType, contentType)
+		}
+
+		if body := string(ctx.Response.Body()); body != test.body {
+			t.Errorf("unexpected body for %s. expected: %q, got: %q", test.uri, test.body, body)
+		}
+	}
+}
+
+func Test_StreamJSONErrors(t *testing.T) {
+	i := 0
+	next := func() (interface{}, error) {
+		if i == 2 {
+			return nil, errors.New("query failed")
+		}
+		i++
+
+		return i, nil
+	}
+
+	buf := new(bytes.Buffer)
+	sourceErr, writeErr := writeStream(bufio.NewWriter(buf), next, defaultJSONCodec, false)
+	if sourceErr == nil || writeErr != nil {
+		t.Errorf("unexpected errors. source: %v, write: %v", sourceErr, writeErr)
+	}
+
+	if buf.String() != "[1,2" {
+		t.Errorf("unexpected body: %q", buf.String())
+	}
+
+	ones := func() (interface{}, error) {
+		return 1, nil
+	}
+
+	sourceErr, writeErr = writeStream(bufio.NewWriterSize(failingWriter{}, 16), ones, defaultJSONCodec, true)
+	if sourceErr != nil || writeErr == nil {
+		t.Errorf("unexpected errors. source: %v, write: %v", sourceErr, writeErr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source, err := streamSource(make(chan int), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	if _, err := source(); err != context.Canceled {
+		t.Errorf("channel source isn't stopped by the context: %v", err)
+	}
+}
+
+func Test_StreamJSONDisconnect(t *testing.T) {
+	stopped := make(chan struct{})
+
+	e := New(Config{Logger: zap.NewNop()})
+	e.GET("/endless", func(c *Context) error {
+		ctx := c.StdContext()
+		ch := make(chan int)
+		go func() {
+			defer close(stopped)
+			for i := 0; ; i++ {
+				select {
+				case ch <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		return c.JSONLines(ch)
+	})
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.Request.SetRequestURI("/endless")
+
+	e.Handler()(ctx)
+
+	// the client disconnects while the stream is written
+	w := &disconnectingWriter{limit: 16}
+	if err := ctx.Response.BodyWriteTo(w); err == nil {
+		t.Error("write to the disconnected client hasn't failed")
+	}
+
+	if body := w.body.String(); !strings.HasPrefix(body, "0\n1\n2\n") {
+		t.Errorf("producer is stopped before the stream is written: %q", body)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("producer isn't signaled after the client disconnected")
+	}
+}
@@ -0,0 +1,147 @@
+package emir
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.uber.org/zap"
+)
+
+func Test_Timeout(t *testing.T) {
+	canceled := make(chan error, 1)
+	deferred := make(chan struct{}, 1)
+
+	var (
+		deadlineExceeded bool
+		requestID        string
+		user             interface{}
+	)
+
+	e := New(Config{Logger: zap.NewNop(), DisableKeepalive: true, TimeoutStatusCode: StatusGatewayTimeout})
+	e.HandleError(func(c *Context, err error) {
+		deadlineExceeded = errors.Is(err, context.DeadlineExceeded)
+		requestID = string(c.ReqHeader().Peek(HeaderXRequestID))
+		user = c.UserValue("user")
+		DefaultErrorHandler(c, err)
+	})
+
+	// the headers and the user values of the middlewares must be kept in the timeout error
+	e.Use(func(c *Context) error {
+		c.ReqHeader().Set(HeaderXRequestID, "request-1")
+		c.RespHeader().Set(HeaderAccessControlAllowOrigin, "*")
+		c.SetUserValue("user", "emir")
+
+		return c.Next()
+	})
+
+	e.GET("/slow", func(c *Context) error {
+		c.Defer(func() {
+			deferred <- struct{}{}
+		})
+
+		ctx := c.StdContext()
+		<-ctx.Done()
+		canceled <- ctx.Err()
+
+		return c.PlainString("too late")
+	}).Timeout(20 * time.Millisecond)
+
+	e.GET("/untimed", func(c *Context) error {
+		return c.PlainString("untimed")
+	})
+
+	api := e.NewGroup("/api")
+	before := api.GET("/before", func(c *Context) error {
+		return nil
+	})
+	api.Timeout(time.Second)
+	v1 := api.NewGroup("/v1")
+	api.GET("/fast", func(c *Context) error {
+		if _, ok := c.StdContext().Deadline(); !ok {
+			return NewBasicError(StatusInternalServerError, "deadline isn't set")
+		}
+
+		return c.PlainString("fast")
+	})
+
+	if before.RouteTimeout != 0 {
+		t.Errorf("group timeout is applied to the previous route: %v", before.RouteTimeout)
+	}
+
+	if route := v1.GET("/", func(c *Context) error { return nil }); route.RouteTimeout != time.Second {
+		t.Errorf("group timeout isn't inherited: %v", route.RouteTimeout)
+	}
+
+	ln := fasthttputil.NewInmemoryListener()
+	go e.Serve(ln)
+	defer e.Shutdown()
+
+	client := &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) {
+			return ln.Dial()
+		},
+	}
+
+	tests := []struct {
+		uri    string
+		status int
+		body   string
+	}{
+		{"/slow", StatusGatewayTimeout, `"message":"Gateway Timeout"`},
+		{"/api/fast", StatusOK, "fast"},
+		{"/untimed", StatusOK, "untimed"},
+	}
+
+	for _, test := range tests {
+		req, resp := fasthttp.AcquireRequest(), fasthttp.AcquireResponse()
+		req.SetRequestURI("http://localhost" + test.uri)
+
+		if err := client.DoTimeout(req, resp, time.Second); err != nil {
+			t.Fatal(err)
+		}
+
+		if status := resp.StatusCode(); status != test.status {
+			t.Errorf("unexpected status code for %s. expected: %d, got: %d", test.uri, test.status, status)
+		}
+
+		if body := string(resp.Body()); !strings.Contains(body, test.body) {
+			t.Errorf("unexpected body for %s. expected: %q, got: %q", test.uri, test.body, body)
+		}
+
+		if origin := string(resp.Header.Peek(HeaderAccessControlAllowOrigin)); origin != "*" {
+			t.Errorf("response header of the middleware is lost for %s: %q", test.uri, origin)
+		}
+
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}
+
+	if requestID != "request-1" || user != "emir" {
+		t.Errorf("request header or user value of the middleware is lost. request id: %q, user: %v", requestID, user)
+	}
+
+	if !deadlineExceeded {
+		t.Error("timeout error doesn't wrap context.DeadlineExceeded")
+	}
+
+	select {
+	case err := <-canceled:
+		if err == nil {
+			t.Error("context isn't canceled")
+		}
+	case <-time.After(time.Second):
+		t.Error("cancellation hasn't reached the handler")
+	}
+
+	select {
+	case <-deferred:
+	case <-time.After(time.Second):
+		t.Error("deferred functions haven't executed after the timed out chain")
+	}
+}
@@ -1,7 +1,10 @@
 package emir
 
 import (
+	"context"
+	"io"
 	"net"
+	"sync"
 	"time"
 
 	stdUrl "net/url"
@@ -19,6 +22,11 @@ type (
 		fastrouter   *fastrouter.Router
 		errorHandler ErrorHandler
 		hosts        map[string]*virtualHost
+		renderers    []Renderer
+		handlerOnce  sync.Once
+		handler      fasthttp.RequestHandler
+		ctx          context.Context
+		cancel       context.CancelFunc
 		cfg          Config
 		Logger       *zap.Logger
 		Router
@@ -58,10 +66,54 @@ type (
 		// TRACE is a shortcut for router.Handle(fasthttp.MethodTrace, path, handlers)
 		TRACE(path string, handlers ...RequestHandler) *Route
 
+		// OPTIONS is a shortcut for router.Handle(fasthttp.MethodOptions, path, handlers)
+		OPTIONS(path string, handlers ...RequestHandler) *Route
+
+		// CONNECT is a shortcut for router.Handle(fasthttp.MethodConnect, path, handlers)
+		CONNECT(path string, handlers ...RequestHandler) *Route
+
+		// Any registers given request handlers with the given path for every method.
+		// Routes registered with a specific method have priority over it.
+		Any(path string, handlers ...RequestHandler) *Route
+
+		// Match registers given request handlers with the given path for each of the given methods.
+		// Each method gets its own route, so they can be configured separately.
+		Match(methods []string, path string, handlers ...RequestHandler) []*Route
+
+		// WS registers a WebSocket endpoint with the given path.
+		// Given middlewares are executed before the upgrade, then the handler is executed with the upgraded connection.
+		// The connection is closed when the handler returns.
+		WS(path string, handler func(*WSConn) error, middlewares ...RequestHandler) *Route
+
+		// Static registers a route that serves the files of the root directory under the given prefix.
+		// Middlewares and the error handler of the router apply to the route.
+		Static(prefix, root string, cfg StaticConfig) *Route
+
+		// SPA registers a single-page app fallback under the given prefix.
+		// Unmatched GET and HEAD requests under the prefix that accept "text/html" are served with the index file,
+		// the other unmatched requests are handled by Config.NotFound.
+		// The fallback with the longest prefix wins. Middlewares and the error handler of the router apply to the fallback.
+		// The fallback is listed by Emir.Routes and documented by Emir.OpenAPI, routes with the same path are preferred
+		// in the document.
+		SPA(prefix, index string, cfg StaticConfig) *Route
+
+		// Mount forwards every method and sub-path under the given prefix to the handler with the prefix stripped.
+		// The handler can be an *Emir, a fasthttp.RequestHandler, an http.Handler or a RequestHandler, it panics otherwise.
+		// Middlewares and the error handler of the router apply to the mounted handler.
+		// The prefix without the trailing slash is redirected to the mounted root if Config.RedirectTrailingSlash is set.
+		// A mounted *Emir can be mounted more than once and served on its own, its request handler is built once
+		// on the first request, so its routes must be registered before it starts serving.
+		// Context.StdContext of a mounted *Emir is derived from the context of the request in the mounting app.
+		Mount(prefix string, handler interface{}) *Route
+
 		// Handler gives routers request handler.
 		// Returns un-nil function only if the router is a virtual host router.
 		Handler() fasthttp.RequestHandler
 
+		// Timeout sets the default timeout of the routes that are registered after it, including the routes of the new groups.
+		// See Route.Timeout.
+		Timeout(d time.Duration)
+
 		// NewGroup creates a subrouter for given path.
 		NewGroup(path string) Router
 
@@ -73,6 +125,34 @@ type (
 
 		// HandleError registers given error handler to the router
 		HandleError(handler ErrorHandler)
+
+		// MapError maps the errors that match given target by errors.Is to given status code and message.
+		// Message is optional, status text is used if it is omitted.
+		MapError(target error, status int, message ...string)
+
+		// MapErrorType maps the errors that match the type of given target by errors.As to given status code and message.
+		// Target must be a value of the error type, e.g. (*MyError)(nil).
+		// Message is optional, status text is used if it is omitted.
+		MapErrorType(target interface{}, status int, message ...string)
+	}
+
+	// JSONCodec is the interface that encodes and decodes JSON.
+	// It is used by every JSON operation of the framework, e.g. Context.JSON, DefaultBinder and the error handlers.
+	JSONCodec interface {
+		Marshal(v interface{}) ([]byte, error)
+		Unmarshal(data []byte, v interface{}) error
+		NewEncoder(w io.Writer) JSONEncoder
+		NewDecoder(r io.Reader) JSONDecoder
+	}
+
+	// JSONEncoder is the interface that wraps the Encode method.
+	JSONEncoder interface {
+		Encode(v interface{}) error
+	}
+
+	// JSONDecoder is the interface that wraps the Decode method.
+	JSONDecoder interface {
+		Decode(v interface{}) error
 	}
 
 	// Binder is the interface that wraps the Bind method.
@@ -96,6 +176,8 @@ type (
 		GracefulShutdown                   bool
 		ErrorHandler                       ErrorHandler
 		Logger                             *zap.Logger
+		JSONCodec                          JSONCodec
+		SSEKeepAliveInterval               time.Duration
 		Name                               string
 		Concurrency                        int
 		DisableKeepalive                   bool
@@ -128,10 +210,16 @@ type (
 		RedirectFixedPath      bool
 		HandleMethodNotAllowed bool
 		HandleOPTIONS          bool
+		LogRoutes              bool
 		GlobalOPTIONS          RequestHandler
 		NotFound               RequestHandler
 		MethodNotAllowed       RequestHandler
 		PanicHandler           func(*Context, interface{})
+		// TimeoutStatusCode is the status code of the error that is rendered when a route exceeds its timeout
+		TimeoutStatusCode int
+
+		//WebSocket settings
+		WebSocket WSConfig
 	}
 
 	//Context context wrapper of fasthttp.RequestCtx to adds extra functionality
@@ -139,10 +227,15 @@ type (
 		*fasthttp.RequestCtx
 		next       bool
 		err        bool
+		index      int
+		chainErr   error
 		deferFuncs []func()
 		route      *Route
 		emir       *Emir
 		stdURL     *stdUrl.URL
+		stdCtx     context.Context
+		stdCancel  context.CancelFunc
+		snapshot   *timeoutSnapshot
 		//TODO: response writer
 	}
 
@@ -151,6 +244,23 @@ type (
 		StatusCode   int
 		ErrorMessage string      `json:"message"`
 		ErrorCode    interface{} `json:"code"`
+		Details      interface{} `json:"details,omitempty"`
+		Cause        error       `json:"-"`
+	}
+
+	// BindErrorDetails describes the field that couldn't be bound
+	BindErrorDetails struct {
+		Field    string `json:"field"`
+		Expected string `json:"expected,omitempty"`
+	}
+
+	// Renderer is the interface that encodes response bodies for a media type.
+	Renderer interface {
+		// ContentType returns the media type of the encoded body e.g. "application/json"
+		ContentType() string
+
+		// Render encodes given value
+		Render(v interface{}) ([]byte, error)
 	}
 
 	// Validator is the interface that wraps the Validate method.
@@ -170,6 +280,36 @@ type (
 		ErrorHandler     ErrorHandler
 		Binder           Binder
 		Validator        Validator
+		// Renderers are preferred over the renderers of Emir by Context.Render
+		Renderers []Renderer
+		RouteDoc  RouteDoc
+		// MaxUploadFiles is the maximum count of files in a multipart form, zero means unlimited
+		MaxUploadFiles int
+		// MaxUploadFileSize is the maximum size of a file in a multipart form, zero means unlimited
+		MaxUploadFileSize int64
+		// RouteDeadline is the deadline of Context.StdContext relative to the start of the request, zero means no deadline
+		RouteDeadline time.Duration
+		// RouteTimeout is the maximum duration of the handler chain, zero means no timeout
+		RouteTimeout time.Duration
+
+		fullPath      string
+		host          string
+		errorMappings *errorRegistry
+		chain         []RequestHandler
+		// fallback is set for the SPA fallbacks, they aren't registered to the router
+		fallback bool
+	}
+
+	// RouteInfo describes a registered route.
+	RouteInfo struct {
+		Name   string
+		Method string
+		// Path is the full path of the route including the group prefixes
+		Path string
+		// Host is the virtual host of the route, it is empty for the default host
+		Host string
+		// Middlewares is the count of before and after middlewares executed for the route
+		Middlewares int
 	}
 
 	ComplexRequestHandler interface {
@@ -179,6 +319,10 @@ type (
 	// RequestHandler must process incoming requests
 	RequestHandler func(*Context) error
 
+	// Middleware wraps the downstream handlers of the chain, next executes them and returns their error.
+	// It can be registered with Wrap by the flat middleware registration methods.
+	Middleware func(next RequestHandler) RequestHandler
+
 	// ErrorHandler must process errror returned by RequestHandler.
 	ErrorHandler func(*Context, error)
 )
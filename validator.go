@@ -0,0 +1,327 @@
+package emir
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// DefaultValidator is the default implementation of the Validator interface.
+	// Rules are read from the "validate" struct tag, e.g. `validate:"required,min=3,email,oneof=a b"`.
+	//
+	// Supported rules are:
+	//  required: value must not be the zero value
+	//  omitempty: skips the other rules if value is the zero value
+	//  min, max: minimum and maximum value for numbers, length for strings, slices and maps
+	//  len: exact length for strings, slices and maps, exact value for numbers
+	//  oneof: value must be one of the space separated values
+	//  email, url, uuid: value must be a valid email address, absolute URL or UUID
+	//
+	// Nested structs, slices and maps of structs are validated recursively.
+	// Unknown rules and invalid params panic when a type is validated first time.
+	DefaultValidator struct {
+	}
+
+	// FieldError represents a rule that a field failed
+	FieldError struct {
+		// Field is the path of the field by the json names e.g. "items[0].name"
+		Field string `json:"field"`
+		Rule  string `json:"rule"`
+		Param string `json:"param,omitempty"`
+	}
+
+	// ValidationErrors is the list of the failed rules returned by DefaultValidator
+	ValidationErrors []FieldError
+)
+
+// validationErrorBody is the response body of ValidationErrors rendered by DefaultErrorHandler
+type validationErrorBody struct {
+	Message string           `json:"message"`
+	Errors  ValidationErrors `json:"errors"`
+}
+
+type validationRule struct {
+	name  string
+	param string
+}
+
+type validatedField struct {
+	index     []int
+	name      string
+	omitEmpty bool
+	rules     []validationRule
+}
+
+var validatedFieldsCache sync.Map
+
+func (errs ValidationErrors) Error() string {
+	var b strings.Builder
+	b.WriteString("validation failed:")
+	for _, err := range errs {
+		b.WriteString(" " + err.Field + " (" + err.Rule)
+		if err.Param != "" {
+			b.WriteString("=" + err.Param)
+		}
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
+
+// Validate implements the Validator#Validate function.
+// It returns ValidationErrors if any rule fails.
+func (*DefaultValidator) Validate(i interface{}) error {
+	var errs ValidationErrors
+	validateValue(reflect.ValueOf(i), "", &errs)
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateValue validates fields of given value if it is a struct or a collection of structs
+func validateValue(v reflect.Value, path string, errs *ValidationErrors) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return
+		}
+
+		for _, field := range validatedFields(v.Type()) {
+			fv := v.FieldByIndex(field.index)
+			fieldPath := field.name
+			if path != "" {
+				fieldPath = path + "." + field.name
+			}
+
+			if field.omitEmpty && fv.IsZero() {
+				continue
+			}
+
+			for _, rule := range field.rules {
+				if !checkRule(fv, rule) {
+					*errs = append(*errs, FieldError{Field: fieldPath, Rule: rule.name, Param: rule.param})
+				}
+			}
+
+			validateValue(fv, fieldPath, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if !mayContainStruct(v.Type().Elem()) {
+			return
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), path+"["+strconv.Itoa(i)+"]", errs)
+		}
+	case reflect.Map:
+		if !mayContainStruct(v.Type().Elem()) {
+			return
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			validateValue(iter.Value(), path+"["+toString(iter.Key().Interface())+"]", errs)
+		}
+	}
+}
+
+// validationRuleParams reports whether the supported validation rules require a param
+var validationRuleParams = map[string]bool{
+	"required": false,
+	"min":      true,
+	"max":      true,
+	"len":      true,
+	"oneof":    true,
+	"email":    false,
+	"url":      false,
+	"uuid":     false,
+}
+
+// validatedFields returns the exported fields of given struct type with their rules.
+// Fields of the embedded structs are included.
+// It panics if a field has an unknown rule or a rule has an invalid param, so typos don't disable the validation.
+func validatedFields(t reflect.Type) []validatedField {
+	if fields, ok := validatedFieldsCache.Load(t); ok {
+		return fields.([]validatedField)
+	}
+
+	var fields []validatedField
+	var visit func(t reflect.Type, index []int)
+	visit = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("validate") == "" {
+				visit(field.Type, fieldIndex)
+				continue
+			}
+
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name, ok := jsonFieldName(field)
+			if !ok {
+				name = field.Name
+			}
+
+			vf := validatedField{index: fieldIndex, name: name}
+			for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+				if rule == "" {
+					continue
+				}
+
+				if rule == "omitempty" {
+					vf.omitEmpty = true
+					continue
+				}
+
+				r := validationRule{name: rule}
+				if idx := strings.IndexByte(rule, '='); idx != -1 {
+					r.name, r.param = rule[:idx], rule[idx+1:]
+				}
+				checkRuleDefinition(t, field, r)
+				vf.rules = append(vf.rules, r)
+			}
+
+			fields = append(fields, vf)
+		}
+	}
+	visit(t, nil)
+
+	validatedFieldsCache.Store(t, fields)
+
+	return fields
+}
+
+// checkRuleDefinition panics if the rule of the field is unknown or its param is invalid
+func checkRuleDefinition(t reflect.Type, field reflect.StructField, rule validationRule) {
+	fieldName := t.String() + "." + field.Name
+
+	requiresParam, ok := validationRuleParams[rule.name]
+	if !ok {
+		panic("emir: unknown validation rule " + strconv.Quote(rule.name) + " of field " + fieldName)
+	}
+
+	if requiresParam != (rule.param != "") {
+		panic("emir: invalid param of validation rule " + strconv.Quote(rule.name) + " of field " + fieldName)
+	}
+
+	if rule.name == "min" || rule.name == "max" || rule.name == "len" {
+		if _, err := strconv.ParseFloat(rule.param, 64); err != nil {
+			panic("emir: validation rule " + strconv.Quote(rule.name) + " of field " + fieldName + " requires a number")
+		}
+	}
+}
+
+// checkRule reports whether given value satisfies the rule.
+func checkRule(v reflect.Value, rule validationRule) bool {
+	if rule.name == "required" {
+		return !isEmpty(v)
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+
+	switch rule.name {
+	case "min":
+		return compareRule(v, rule.param, func(value, param float64) bool { return value >= param })
+	case "max":
+		return compareRule(v, rule.param, func(value, param float64) bool { return value <= param })
+	case "len":
+		return compareRule(v, rule.param, func(value, param float64) bool { return value == param })
+	case "oneof":
+		value := valueString(v)
+		for _, option := range strings.Fields(rule.param) {
+			if option == value {
+				return true
+			}
+		}
+
+		return false
+	case "email":
+		address, err := mail.ParseAddress(valueString(v))
+		return err == nil && address.Address == valueString(v)
+	case "url":
+		u, err := url.Parse(valueString(v))
+		return err == nil && u.Scheme != "" && u.Host != ""
+	case "uuid":
+		_, err := uuid.Parse(valueString(v))
+		return err == nil
+	}
+
+	return true
+}
+
+// compareRule compares the number or the length of given value with the param
+func compareRule(v reflect.Value, param string, compare func(value, param float64) bool) bool {
+	p, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return compare(float64(utf8.RuneCountInString(v.String())), p)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return compare(float64(v.Len()), p)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compare(float64(v.Int()), p)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compare(float64(v.Uint()), p)
+	case reflect.Float32, reflect.Float64:
+		return compare(v.Float(), p)
+	}
+
+	return false
+}
+
+func mayContainStruct(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return mayContainStruct(t.Elem())
+	}
+
+	return false
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+
+	return v.IsZero()
+}
+
+func valueString(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+
+	return toString(v.Interface())
+}
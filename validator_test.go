@@ -0,0 +1,140 @@
+package emir
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type validatorAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type validatorUser struct {
+	Name      string             `json:"name" validate:"required,min=3"`
+	Email     string             `json:"email" validate:"omitempty,email"`
+	Role      string             `json:"role" validate:"oneof=admin user"`
+	Age       int                `json:"age" validate:"min=18,max=99"`
+	Tags      []string           `json:"tags" validate:"max=2"`
+	Website   *string            `json:"website" validate:"omitempty,url"`
+	Addresses []validatorAddress `json:"addresses"`
+}
+
+func Test_DefaultValidator(t *testing.T) {
+	v := &DefaultValidator{}
+	website := "https://example.com"
+
+	valid := validatorUser{
+		Name:      "emir",
+		Email:     "emir@example.com",
+		Role:      "admin",
+		Age:       30,
+		Tags:      []string{"a"},
+		Website:   &website,
+		Addresses: []validatorAddress{{City: "Istanbul"}},
+	}
+
+	if err := v.Validate(&valid); err != nil {
+		t.Fatal(err)
+	}
+
+	invalidWebsite := "example"
+	invalid := validatorUser{
+		Name:      "em",
+		Email:     "not an email",
+		Role:      "guest",
+		Age:       10,
+		Tags:      []string{"a", "b", "c"},
+		Website:   &invalidWebsite,
+		Addresses: []validatorAddress{{City: "Istanbul"}, {}},
+	}
+
+	err := v.Validate(invalid)
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("unexpected error type: %T", err)
+	}
+
+	expected := ValidationErrors{
+		{Field: "name", Rule: "min", Param: "3"},
+		{Field: "email", Rule: "email"},
+		{Field: "role", Rule: "oneof", Param: "admin user"},
+		{Field: "age", Rule: "min", Param: "18"},
+		{Field: "tags", Rule: "max", Param: "2"},
+		{Field: "website", Rule: "url"},
+		{Field: "addresses[1].city", Rule: "required"},
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	for i := range expected {
+		if errs[i] != expected[i] {
+			t.Errorf("unexpected error. expected: %+v, got: %+v", expected[i], errs[i])
+		}
+	}
+}
+
+func Test_DefaultValidatorInvalidRules(t *testing.T) {
+	tests := []interface{}{
+		&struct {
+			Name string `validate:"requird"`
+		}{},
+		&struct {
+			Age int `validate:"gte=1"`
+		}{},
+		&struct {
+			Age int `validate:"min=ten"`
+		}{},
+		&struct {
+			Role string `validate:"oneof"`
+		}{},
+		&struct {
+			Email string `validate:"email=true"`
+		}{},
+	}
+
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("invalid rule of %T hasn't panicked", test)
+				}
+			}()
+
+			(&DefaultValidator{}).Validate(test)
+		}()
+	}
+}
+
+func Test_ValidationErrorResponse(t *testing.T) {
+	e := New(Config{})
+
+	e.NewGroup("/api").POST("/users", func(c *Context) error {
+		return c.Validate(&validatorUser{Name: "emir", Role: "user", Age: 10})
+	})
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(MethodPost)
+	ctx.Request.SetRequestURI("/api/users")
+
+	e.Handler()(ctx)
+
+	if code := ctx.Response.StatusCode(); code != StatusUnprocessableEntity {
+		t.Fatalf("unexpected status code. expected: %d, got: %d", StatusUnprocessableEntity, code)
+	}
+
+	body := struct {
+		Errors []FieldError `json:"errors"`
+	}{}
+
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(body.Errors) != 1 || body.Errors[0].Field != "age" {
+		t.Errorf("unexpected response body: %s", ctx.Response.Body())
+	}
+}
@@ -1,37 +1,52 @@
 package emir
 
 import (
+	"strings"
+	"time"
+
 	fastrouter "github.com/fasthttp/router"
 	"github.com/valyala/fasthttp"
 )
 
 type virtualHost struct {
 	emir             *Emir
+	hostname         string
 	Router           *fastrouter.Router
 	subRouters       []Router
 	routes           []*Route
+	fallbacks        []*spaFallback
 	middlewares      []RequestHandler
 	afterMiddlewares []RequestHandler
 	errorHandler     ErrorHandler
+	errorMappings    *errorRegistry
+	timeout          time.Duration
 	Binder           Binder
 	Validator        Validator
 }
 
 func (vh *virtualHost) Handle(path string, method string, handlers ...RequestHandler) *Route {
-	route := &Route{
-		RouteName:    path,
-		Path:         path,
-		Method:       method,
-		Handlers:     handlers,
-		ErrorHandler: vh.errorHandler,
-		Validator:    vh.Validator,
-		Binder:       vh.Binder,
-	}
+	route := vh.newRoute(path, method, handlers)
 	vh.routes = append(vh.routes, route)
 
 	return route
 }
 
+func (vh *virtualHost) newRoute(path string, method string, handlers []RequestHandler) *Route {
+	return &Route{
+		RouteName:     path,
+		Path:          path,
+		Method:        method,
+		Handlers:      handlers,
+		ErrorHandler:  vh.errorHandler,
+		Validator:     vh.Validator,
+		Binder:        vh.Binder,
+		fullPath:      path,
+		host:          vh.hostname,
+		errorMappings: vh.errorMappings,
+		RouteTimeout:  vh.timeout,
+	}
+}
+
 func (vh *virtualHost) Use(handlers ...RequestHandler) Router {
 	if vh.middlewares == nil {
 		vh.middlewares = []RequestHandler{}
@@ -60,10 +75,22 @@ func (vh *virtualHost) After(handlers ...RequestHandler) Router {
 	return vh
 }
 
+func (vh *virtualHost) Timeout(d time.Duration) {
+	vh.timeout = d
+}
+
 func (vh *virtualHost) HandleError(handler ErrorHandler) {
 	vh.errorHandler = handler
 }
 
+func (vh *virtualHost) MapError(target error, status int, message ...string) {
+	vh.errorMappings.mapError(target, status, message)
+}
+
+func (vh *virtualHost) MapErrorType(target interface{}, status int, message ...string) {
+	vh.errorMappings.mapErrorType(target, status, message)
+}
+
 func (vh *virtualHost) GET(path string, handlers ...RequestHandler) *Route {
 	return vh.Handle(path, MethodGet, handlers...)
 }
@@ -92,55 +119,127 @@ func (vh *virtualHost) TRACE(path string, handlers ...RequestHandler) *Route {
 	return vh.Handle(path, MethodTrace, handlers...)
 }
 
+func (vh *virtualHost) OPTIONS(path string, handlers ...RequestHandler) *Route {
+	return vh.Handle(path, MethodOptions, handlers...)
+}
+
+func (vh *virtualHost) CONNECT(path string, handlers ...RequestHandler) *Route {
+	return vh.Handle(path, MethodConnect, handlers...)
+}
+
+func (vh *virtualHost) Any(path string, handlers ...RequestHandler) *Route {
+	return vh.Handle(path, fastrouter.MethodWild, handlers...)
+}
+
+func (vh *virtualHost) Match(methods []string, path string, handlers ...RequestHandler) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, vh.Handle(path, method, handlers...))
+	}
+
+	return routes
+}
+
+func (vh *virtualHost) WS(path string, handler func(*WSConn) error, middlewares ...RequestHandler) *Route {
+	return vh.Handle(path, MethodGet, newWSHandler(handler)).Use(middlewares...)
+}
+
+func (vh *virtualHost) Static(prefix, root string, cfg StaticConfig) *Route {
+	return vh.Handle(strings.TrimSuffix(prefix, "/")+"/{filepath:*}", fastrouter.MethodWild, newStaticHandler(root, cfg))
+}
+
+func (vh *virtualHost) SPA(prefix, index string, cfg StaticConfig) *Route {
+	route := vh.newRoute(prefix, MethodGet, []RequestHandler{newSPAHandler(index, cfg)})
+	route.fallback = true
+	vh.fallbacks = append(vh.fallbacks, &spaFallback{prefix: prefix, route: route})
+
+	return route
+}
+
+func (vh *virtualHost) Mount(prefix string, handler interface{}) *Route {
+	return vh.Handle(strings.TrimSuffix(prefix, "/")+"/{mountpath:*}", fastrouter.MethodWild, newMountHandler(prefix, handler))
+}
+
 func (vh *virtualHost) Handler() fasthttp.RequestHandler {
 	for _, route := range vh.routes {
-		vh.Router.Handle(route.Method, route.Path, func(fctx *fasthttp.RequestCtx) {
-			ctx := acquireCtx(fctx)
-			defer func() {
-				for _, deferFunc := range ctx.deferFuncs {
-					deferFunc()
-				}
-				releaseCtx(ctx)
-			}()
-
-			ctx.route = route
-			ctx.emir = vh.emir
-
-			chain := append(vh.middlewares, route.Middlewares...)
-			chain = append(chain, route.Handlers...)
-			chain = append(chain, route.AfterMiddlewares...)
-			chain = append(chain, vh.afterMiddlewares...)
-
-			for _, handler := range chain {
-				ctx.next = false
-				if err := handler(ctx); err != nil {
-					route.ErrorHandler(ctx, err)
-					return
-				}
-
-				if !ctx.next {
-					return
-				}
-			}
-		})
+		vh.Router.Handle(route.Method, route.Path, vh.handle(route))
+	}
+
+	for _, fallback := range vh.fallbacks {
+		fallback.handler = vh.handle(fallback.route)
 	}
 
 	for _, subrouter := range vh.subRouters {
 		subrouter.Handler()
 	}
 
+	vh.Router.NotFound = spaNotFound(vh.registeredFallbacks(), vh.Router.NotFound)
+
 	return vh.Router.Handler
 }
 
+// handle compiles the chain of the route with the middlewares of the router and returns its request handler
+func (vh *virtualHost) handle(route *Route) fasthttp.RequestHandler {
+	route.compile(vh.middlewares, vh.afterMiddlewares)
+
+	return dispatch(vh.emir, route)
+}
+
 func (vh *virtualHost) NewGroup(path string) Router {
 	newRouter := &router{
+		emir:             vh.emir,
+		host:             vh.hostname,
 		Group:            vh.Router.Group(path),
-		middlewares:      vh.middlewares,
-		afterMiddlewares: vh.afterMiddlewares,
+		prefix:           path,
+		middlewares:      append([]RequestHandler(nil), vh.middlewares...),
+		afterMiddlewares: append([]RequestHandler(nil), vh.afterMiddlewares...),
 		errorHandler:     vh.errorHandler,
+		errorMappings:    newErrorRegistry(vh.errorMappings),
+		timeout:          vh.timeout,
+		Binder:           vh.Binder,
+		Validator:        vh.Validator,
 	}
 
 	vh.subRouters = append(vh.subRouters, newRouter)
 
 	return newRouter
 }
+
+func (vh *virtualHost) registeredRoutes() []*Route {
+	routes := append([]*Route(nil), vh.routes...)
+	for _, fallback := range vh.fallbacks {
+		routes = append(routes, fallback.route)
+	}
+
+	for _, subrouter := range vh.subRouters {
+		routes = append(routes, subrouter.(*router).registeredRoutes()...)
+	}
+
+	return routes
+}
+
+func (vh *virtualHost) registeredFallbacks() []*spaFallback {
+	fallbacks := append([]*spaFallback(nil), vh.fallbacks...)
+	for _, subrouter := range vh.subRouters {
+		fallbacks = append(fallbacks, subrouter.(*router).registeredFallbacks()...)
+	}
+
+	return fallbacks
+}
+
+func (vh *virtualHost) routeInfos() []RouteInfo {
+	infos := make([]RouteInfo, 0, len(vh.routes)+len(vh.fallbacks))
+	for _, route := range vh.routes {
+		infos = append(infos, route.info(len(vh.middlewares)+len(vh.afterMiddlewares)))
+	}
+
+	for _, fallback := range vh.fallbacks {
+		infos = append(infos, fallback.route.info(len(vh.middlewares)+len(vh.afterMiddlewares)))
+	}
+
+	for _, subrouter := range vh.subRouters {
+		infos = append(infos, subrouter.(*router).routeInfos()...)
+	}
+
+	return infos
+}
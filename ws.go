@@ -0,0 +1,184 @@
+package emir
+
+import (
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// wsWriteWait is the time allowed to write a control message to the peer
+const wsWriteWait = 10 * time.Second
+
+type (
+	// WSConfig carries configuration for WebSocket endpoints
+	WSConfig struct {
+		// HandshakeTimeout is the duration for the handshake to complete
+		HandshakeTimeout time.Duration
+		// ReadBufferSize and WriteBufferSize are the I/O buffer sizes in bytes, they don't limit the size of the messages
+		ReadBufferSize  int
+		WriteBufferSize int
+		// Subprotocols are the supported subprotocols by preference order
+		Subprotocols []string
+		// CheckOrigin reports whether the origin of the request is allowed.
+		// Requests without Origin header and the same origin requests are allowed if it is nil.
+		CheckOrigin func(c *Context) bool
+		// EnableCompression enables the per message compression negotiation
+		EnableCompression bool
+		// ReadLimit is the maximum size of a message in bytes, zero means unlimited
+		ReadLimit int64
+		// PingInterval is the interval of the ping messages, zero disables the ping messages
+		PingInterval time.Duration
+		// PongTimeout is the time allowed to read a pong message, it is twice the ping interval if it is zero
+		PongTimeout time.Duration
+	}
+
+	// WSConn is a WebSocket connection upgraded by Router.WS
+	// The user values of the request are copied to the connection since the request is released after the upgrade.
+	WSConn struct {
+		*websocket.Conn
+		userValues  map[string]interface{}
+		requestID   string
+		subprotocol string
+		logger      *zap.Logger
+	}
+)
+
+// UserValue returns the user value of the upgraded request by key.
+func (c *WSConn) UserValue(key string) interface{} {
+	return c.userValues[key]
+}
+
+// Param returns the path parameter of the upgraded request by name.
+func (c *WSConn) Param(name string) string {
+	switch v := c.userValues[name].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+
+	return ""
+}
+
+// RequestID returns the request id of the upgraded request.
+func (c *WSConn) RequestID() string {
+	return c.requestID
+}
+
+// Subprotocol returns the negotiated subprotocol.
+func (c *WSConn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// Logger returns the logger.
+func (c *WSConn) Logger() *zap.Logger {
+	return c.logger
+}
+
+// newWSHandler returns a request handler that upgrades the request and runs given handler with the connection.
+// Handshake failures are returned as BasicErrors to be rendered by the error handler of the route.
+func newWSHandler(handler func(*WSConn) error) RequestHandler {
+	return func(c *Context) error {
+		cfg := c.emir.cfg.WebSocket
+
+		status := 0
+		upgrader := websocket.FastHTTPUpgrader{
+			HandshakeTimeout:  cfg.HandshakeTimeout,
+			ReadBufferSize:    cfg.ReadBufferSize,
+			WriteBufferSize:   cfg.WriteBufferSize,
+			Subprotocols:      cfg.Subprotocols,
+			EnableCompression: cfg.EnableCompression,
+			Error: func(_ *fasthttp.RequestCtx, code int, _ error) {
+				status = code
+			},
+		}
+
+		if cfg.CheckOrigin != nil {
+			upgrader.CheckOrigin = func(*fasthttp.RequestCtx) bool {
+				return cfg.CheckOrigin(c)
+			}
+		}
+
+		// the request is released before the connection is handed over, so everything is copied here
+		conn := &WSConn{
+			userValues: map[string]interface{}{},
+			requestID:  string(c.RequestID()),
+			logger:     c.emir.Logger,
+		}
+		c.VisitUserValues(func(key []byte, value interface{}) {
+			if b, ok := value.([]byte); ok {
+				value = append([]byte(nil), b...)
+			}
+			conn.userValues[string(key)] = value
+		})
+		keepHijackedConns := c.emir.cfg.KeepHijackedConns
+
+		err := upgrader.Upgrade(c.RequestCtx, func(ws *websocket.Conn) {
+			if keepHijackedConns {
+				defer ws.Close()
+			}
+
+			conn.Conn = ws
+			conn.serve(handler, cfg)
+		})
+		if err != nil {
+			return WrapBasicError(err, status, err.Error())
+		}
+
+		conn.subprotocol = string(c.Response.Header.Peek(HeaderSecWebSocketProtocol))
+
+		return nil
+	}
+}
+
+// serve runs given handler with the connection.
+// Ping messages are sent by the configured interval until the handler returns.
+// The connection is closed with a close message when the handler returns.
+func (c *WSConn) serve(handler func(*WSConn) error, cfg WSConfig) {
+	if cfg.ReadLimit > 0 {
+		c.SetReadLimit(cfg.ReadLimit)
+	}
+
+	if cfg.PingInterval > 0 {
+		pongTimeout := cfg.PongTimeout
+		if pongTimeout <= 0 {
+			pongTimeout = 2 * cfg.PingInterval
+		}
+
+		c.SetReadDeadline(time.Now().Add(pongTimeout))
+		c.SetPongHandler(func(string) error {
+			return c.SetReadDeadline(time.Now().Add(pongTimeout))
+		})
+
+		done := make(chan struct{})
+		defer close(done)
+
+		go c.ping(cfg.PingInterval, done)
+	}
+
+	closeCode, closeText := websocket.CloseNormalClosure, ""
+	if err := handler(c); err != nil {
+		c.logger.Error("websocket handler failed", zap.Error(err), zap.String(requestIDLogKey, c.requestID))
+		closeCode, closeText = websocket.CloseInternalServerErr, "internal server error"
+	}
+
+	c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, closeText), time.Now().Add(wsWriteWait))
+}
+
+func (c *WSConn) ping(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package emir
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.uber.org/zap"
+)
+
+func Test_WS(t *testing.T) {
+	e := New(Config{
+		Logger:            zap.NewNop(),
+		KeepHijackedConns: true,
+		WebSocket: WSConfig{
+			Subprotocols: []string{"chat.v2", "chat.v1"},
+			ReadLimit:    16,
+			PingInterval: 10 * time.Millisecond,
+		},
+	})
+
+	auth := func(c *Context) error {
+		if c.Query("token") != "secret" {
+			return NewBasicError(StatusUnauthorized, "unauthorized")
+		}
+
+		c.SetUserValue("user", "emir")
+		return c.Next()
+	}
+
+	e.WS("/rooms/{room}", func(conn *WSConn) error {
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return nil
+			}
+
+			reply := conn.UserValue("user").(string) + "@" + conn.Param("room") + "/" + conn.Subprotocol() + ": " + string(message)
+			if err := conn.WriteMessage(messageType, []byte(reply)); err != nil {
+				return err
+			}
+		}
+	}, auth)
+
+	ln := fasthttputil.NewInmemoryListener()
+	go e.Serve(ln)
+	defer e.Shutdown()
+
+	dialer := websocket.Dialer{
+		NetDial: func(network, addr string) (net.Conn, error) {
+			return ln.Dial()
+		},
+		Subprotocols: []string{"chat.v1", "chat.v2"},
+	}
+
+	_, resp, err := dialer.Dial("ws://localhost/rooms/general", nil)
+	if err == nil || resp == nil || resp.StatusCode != StatusUnauthorized {
+		t.Fatalf("middleware hasn't rejected the upgrade: %v", err)
+	}
+
+	conn, resp, err := dialer.Dial("ws://localhost/rooms/general?token=secret", http.Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if protocol := resp.Header.Get(HeaderSecWebSocketProtocol); protocol != "chat.v2" {
+		t.Errorf("unexpected subprotocol: %s", protocol)
+	}
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+
+		return conn.WriteControl(websocket.PongMessage, nil, time.Now().Add(time.Second))
+	})
+
+	type result struct {
+		message []byte
+		err     error
+	}
+
+	results := make(chan result)
+	go func() {
+		for {
+			_, message, err := conn.ReadMessage()
+			results <- result{message, err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, message := range []string{"hi", "still here"} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+			t.Fatal(err)
+		}
+
+		r := <-results
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+
+		if expected := "emir@general/chat.v2: " + message; string(r.message) != expected {
+			t.Errorf("unexpected message. expected: %s, got: %s", expected, r.message)
+		}
+
+		select {
+		case <-pinged:
+		case <-time.After(time.Second):
+			t.Error("ping hasn't received")
+		}
+
+		// waits longer than the pong timeout, the connection must be kept alive by the pongs
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("this message exceeds the read limit")); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := <-results; !websocket.IsCloseError(r.err, websocket.CloseMessageTooBig, websocket.CloseNormalClosure) {
+		t.Errorf("connection isn't closed by the read limit: %v", r.err)
+	}
+}